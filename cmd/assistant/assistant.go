@@ -25,109 +25,80 @@
 package main
 
 import (
+	"fmt"
 	"math/rand"
 	"portfolio/internal/asset"
+	"portfolio/internal/optimizer"
 	"portfolio/internal/wallet"
 	"portfolio/internal/watchlist"
 	"time"
 )
 
-// Assistant Configuration
-const (
-	minAllocation = 0.02 // Minimum Allocation for an Asset
-	maxAllocation = 0.15 // Maximum Allocation for an Asset
-)
-
-// Assets.
-var assets []*asset.Asset
-
-// Generates a random allocation.
-func NewAllocation() []float32 {
-	allocation := make([]float32, len(assets))
+// Builds the optimizer selected in the command line.
+func newOptimizer(verbose bool) (optimizer.Optimizer, error) {
 
-	for i := 0; i < len(assets); i++ {
-		allocation[i] = rand.Float32()
+	// Multi-objective mode.
+	if paretoMode {
+		nsga := optimizer.NewDefaultNSGA2()
+		nsga.SetVerbose(verbose)
+		return nsga, nil
 	}
 
-	return allocation
-}
-
-// Computes the performance valuation of an allocation.
-func costEval(allocation []float32) float32 {
-	value := float32(0.0)
-
-	for i := range allocation {
-		value += assets[i].Cost() * allocation[i]
+	target := float32(targetReturn / 100.0)
+	riskFree := float32(riskFreeRate / 100.0)
+
+	switch optimizerName {
+	case "ga":
+		ga := optimizer.NewDefaultGeneticAlgorithm()
+		ga.SetVerbose(verbose)
+		return ga, nil
+	case "minvar":
+		return optimizer.NewMeanVariance(optimizer.MinVariance, target, riskFree), nil
+	case "sharpe":
+		return optimizer.NewMeanVariance(optimizer.MaxSharpe, target, riskFree), nil
+	case "target":
+		return optimizer.NewMeanVariance(optimizer.TargetReturn, target, riskFree), nil
 	}
 
-	return value
+	return nil, fmt.Errorf("unknown optimizer " + optimizerName)
 }
 
-// Computes the performance valuation of an allocation.
-func perfEval(allocation []float32) float32 {
-	performance := float32(0.0)
-
-	for i := range allocation {
-		performance += assets[i].Performance() * allocation[i]
+// Builds the covariance-based risk terms selected in the command line.
+func newRiskTerms() optimizer.RiskTerms {
+	return optimizer.RiskTerms{
+		Volatility:      float32(volWeight),
+		Diversification: float32(divRatioWeight),
+		Bets:            float32(betsWeight),
 	}
-
-	return performance
-}
-
-func eval(allocation []float32) float32 {
-	cost := costEval(allocation)
-	performance := perfEval(allocation)
-	risk := riskEval(allocation)
-
-	return (cost + performance + risk) / 3.0
 }
 
-// Computes the risk valuation of an allocation.
-func riskEval(allocation []float32) float32 {
-	var risk float32
-	classes := []float32{0, 0, 0, 0, 0}
-
-	// Compute asset allocation in each class.
-	for i := range allocation {
-
-		// Too risky.
-		if allocation[i] >= maxAllocation {
-			return 0.0
-		}
-
-		classes[assets[i].Class()] += allocation[i]
-	}
-
-	for i := range classes {
-		if classes[i] > risk {
-			risk = classes[i]
-		}
+// Builds the weights of risk measures selected in the command line.
+func newRiskWeights() asset.RiskWeights {
+	measures, err := asset.ParseRiskWeights(riskWeights)
+	if err != nil {
+		panic(err.Error())
 	}
 
-	return (1 - risk) / 10.0
+	return measures
 }
 
-func AssistantRun(watchlist *watchlist.Watchlist, verbose bool) *wallet.Wallet {
-
-	assets = watchlist.Assets()
-	wallet := wallet.New("Recommended Wallet")
+// Runs the assistant on a watchlist, using a given optimizer, objective
+// function and (optional) allocation constraints.
+func AssistantRun(watchlist *watchlist.Watchlist, assistant optimizer.Optimizer, objective optimizer.Objective, constraints *optimizer.Constraints) (*wallet.Wallet, *optimizer.Result, error) {
 
-	rand.Seed(time.Hour.Nanoseconds())
+	rng := rand.New(rand.NewSource(time.Hour.Nanoseconds()))
+	problem := optimizer.NewProblem(watchlist.Assets(), objective, rng)
+	problem.Constraints = constraints
+	problem.RiskWeights = newRiskWeights()
+	problem.RiskTerms = newRiskTerms()
 
-	assistant := newGeneticAlgorithm(populationSize,
-		selectionRatio,
-		eliteRatio,
-		mutationRatio,
-	)
-
-	bestSolution := assistant.run(verbose)
-	allocation := make(map[int]float32)
-	for i, a := range watchlist.Assets() {
-		assetID := a.ID()
-		allocation[assetID] = bestSolution.dna[i]
+	result, err := assistant.Optimize(problem)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	wallet.SetAllocation(allocation)
+	wallet := wallet.New("Recommended Wallet")
+	wallet.SetAllocation(result.Allocation)
 
-	return wallet
+	return wallet, result, nil
 }
@@ -0,0 +1,79 @@
+/*
+ * MIT License
+ *
+ * Copyright(c) 2020 Pedro Henrique Penna <pedrohenriquepenna@gmail.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"portfolio/internal/backtest"
+	"portfolio/internal/optimizer"
+	"portfolio/internal/wallet"
+	"portfolio/internal/watchlist"
+	"time"
+)
+
+// Backtests an optimizer on a watchlist, against holding a wallet.
+func BacktestRun(watchlist *watchlist.Watchlist, assistant optimizer.Optimizer, objective optimizer.Objective, constraints *optimizer.Constraints, myWallet *wallet.Wallet) error {
+	var err error
+
+	bt := backtest.New(watchlist.Assets(), assistant, objective)
+	bt.Constraints = constraints
+	bt.RiskWeights = newRiskWeights()
+	bt.RiskTerms = newRiskTerms()
+	bt.Benchmark = myWallet.Allocation()
+	bt.Period = backtestPeriod
+	bt.Lookback = lookback
+
+	if backtestFrom != "" {
+		if bt.Start, err = time.Parse("2006-01-02", backtestFrom); err != nil {
+			return err
+		}
+	}
+	if backtestTo != "" {
+		if bt.End, err = time.Parse("2006-01-02", backtestTo); err != nil {
+			return err
+		}
+	}
+
+	// The backtest ends as of the reference date, at the latest.
+	if asOfDate != "" {
+		date, err := time.Parse("2006-01-02", asOfDate)
+		if err != nil {
+			return err
+		}
+		if backtestTo == "" {
+			bt.End = date
+		} else if bt.End.After(date) {
+			return fmt.Errorf("backtest end date is after the reference date")
+		}
+	}
+
+	report, err := bt.Run()
+	if err != nil {
+		return err
+	}
+
+	return report.Write(os.Stdout)
+}
@@ -0,0 +1,62 @@
+/*
+ * MIT License
+ *
+ * Copyright(c) 2020 Pedro Henrique Penna <pedrohenriquepenna@gmail.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"portfolio/internal/asset"
+	"portfolio/internal/benchmark"
+	"portfolio/internal/wallet"
+)
+
+// Prints the performance of assets and wallets relative to a benchmark.
+func BenchmarkRun(bench *benchmark.Benchmark, assets []*asset.Asset, wallets ...*wallet.Wallet) {
+	bench.WriteHeader(os.Stdout)
+
+	for _, a := range assets {
+		dates, index := a.TotalReturnIndex()
+		metrics, err := bench.Compare(dates, index)
+		if err != nil {
+			fmt.Printf("  %-20s %s\n", a.Ticker(), err.Error())
+			continue
+		}
+		metrics.Write(os.Stdout, a.Ticker())
+	}
+
+	for _, w := range wallets {
+		dates, index, err := w.TotalReturnIndex()
+		if err == nil {
+			var metrics *benchmark.Metrics
+			if metrics, err = bench.Compare(dates, index); err == nil {
+				metrics.Write(os.Stdout, w.Name())
+				continue
+			}
+		}
+		fmt.Printf("  %-20s %s\n", w.Name(), err.Error())
+	}
+
+	fmt.Printf("\n")
+}
@@ -26,14 +26,57 @@ package main
 
 import (
 	"flag"
+	"portfolio/internal/asset"
+	"portfolio/internal/backtest"
+	"portfolio/internal/benchmark"
+	"portfolio/internal/optimizer"
+	"strings"
 )
 
 // Command Line Arguments
 var (
-	saveWallet     bool   // Save wallet?
-	walletFilename string // Wallet File name
-	printStats     bool   // Print statistics?
-	printWallet    bool   // Print wallet?
+	saveWallet          bool    // Save wallet?
+	walletFilename      string  // Wallet File name
+	printStats          bool    // Print statistics?
+	printWallet         bool    // Print wallet?
+	printIndex          bool    // Print total return index?
+	objectiveName       string  // Objective Function
+	costWeight          float64 // Weight of Cost
+	perfWeight          float64 // Weight of Performance
+	riskWeight          float64 // Weight of Risk
+	dataWeight          float64 // Weight of the Penalty on Incomplete Data
+	riskWeights         string  // Weights of Risk Measures
+	volWeight           float64 // Weight of Portfolio Volatility
+	divRatioWeight      float64 // Weight of the Diversification Ratio
+	betsWeight          float64 // Weight of the Effective Number of Bets
+	printCorrelation    bool    // Print correlation matrix?
+	missingPolicy       string  // Missing-Data Policy
+	resamplingName      string  // Resampling Method of Daily Prices
+	constraintsFilename string  // Constraints File Name
+	paretoMode          bool    // Multi-Objective Mode?
+	frontFilename       string  // Pareto Front File Name
+	optimizerName       string  // Optimizer
+	targetReturn        float64 // Target Annual Return (percent)
+	riskFreeRate        float64 // Annual Risk-Free Rate (percent)
+	runBacktest         bool    // Run backtest?
+	backtestFrom        string  // Backtest Start Date
+	backtestTo          string  // Backtest End Date
+	backtestPeriod      int     // Rebalance Period (months)
+	asOfDate            string  // Reference Date for Statistics
+	lookback            int     // Lookback Window for Statistics (months)
+	currentWallet       string  // Current Wallet File Name
+	rebalanceWallet     bool    // Print rebalance orders?
+	minTrade            float64 // Minimum Trade Size (R$)
+	contribution        float64 // New Contribution (R$)
+	ledgerFilename      string  // Ledger File Name
+	printHistory        bool    // Print ledger history?
+	printTax            bool    // Print tax report?
+	taxFilename         string  // Tax Report File Name
+	annualYear          int     // Year of the Annual Tax Declaration
+	annualFilename      string  // Annual Tax Declaration File Name
+	registryFilename    string  // Asset Registry File Name
+	taxonomyFilename    string  // Class Taxonomy File Name
+	benchmarkName       string  // Benchmark File Name
 )
 
 // Parses command line arguments.
@@ -51,5 +94,119 @@ func parseArgs() {
 	printWalletHelp := "Print wallet?"
 	flag.BoolVar(&printWallet, "print", false, printWalletHelp)
 
+	printIndexHelp := "Print total return index of wallets?"
+	flag.BoolVar(&printIndex, "index", false, printIndexHelp)
+
+	objectiveHelp := "Objective function (" + strings.Join(optimizer.Objectives(), ", ") + ")"
+	flag.StringVar(&objectiveName, "objective", "blend", objectiveHelp)
+
+	costWeightHelp := "Weight of cost in the objective function"
+	flag.Float64Var(&costWeight, "wcost", 1.0, costWeightHelp)
+
+	perfWeightHelp := "Weight of performance in the objective function"
+	flag.Float64Var(&perfWeight, "wperf", 1.0, perfWeightHelp)
+
+	riskWeightHelp := "Weight of risk in the objective function"
+	flag.Float64Var(&riskWeight, "wrisk", 1.0, riskWeightHelp)
+
+	dataWeightHelp := "Weight of the penalty on incomplete data in the objective function"
+	flag.Float64Var(&dataWeight, "wdata", 0.0, dataWeightHelp)
+
+	riskWeightsHelp := "Weights of risk measures, as name=weight pairs (" + strings.Join(asset.RiskMeasures(), ", ") + ")"
+	flag.StringVar(&riskWeights, "riskweights", "", riskWeightsHelp)
+
+	volWeightHelp := "Weight of the portfolio volatility in the risk of the objective function"
+	flag.Float64Var(&volWeight, "wvol", 0.0, volWeightHelp)
+
+	divRatioWeightHelp := "Weight of the diversification ratio in the risk of the objective function"
+	flag.Float64Var(&divRatioWeight, "wdivratio", 0.0, divRatioWeightHelp)
+
+	betsWeightHelp := "Weight of the effective number of bets in the risk of the objective function"
+	flag.Float64Var(&betsWeight, "wbets", 0.0, betsWeightHelp)
+
+	printCorrelationHelp := "Print correlation matrix of the watchlist?"
+	flag.BoolVar(&printCorrelation, "correlation", false, printCorrelationHelp)
+
+	missingPolicyHelp := "Missing-data policy (" + strings.Join(asset.Policies(), ", ") + ")"
+	flag.StringVar(&missingPolicy, "missing", "skip", missingPolicyHelp)
+
+	resamplingHelp := "Resampling method of daily prices (" + strings.Join(asset.Resamplings(), ", ") + ")"
+	flag.StringVar(&resamplingName, "resample", "monthend", resamplingHelp)
+
+	constraintsHelp := "Name of the constraints file"
+	flag.StringVar(&constraintsFilename, "constraints", "", constraintsHelp)
+
+	paretoHelp := "Run in multi-objective mode and print the Pareto front?"
+	flag.BoolVar(&paretoMode, "pareto", false, paretoHelp)
+
+	frontFilenameHelp := "Name of the CSV file to export the Pareto front"
+	flag.StringVar(&frontFilename, "front", "", frontFilenameHelp)
+
+	optimizerHelp := "Optimizer (ga, minvar, sharpe, target)"
+	flag.StringVar(&optimizerName, "optimizer", "ga", optimizerHelp)
+
+	targetReturnHelp := "Target annual return in percent (target optimizer)"
+	flag.Float64Var(&targetReturn, "target", 10.0, targetReturnHelp)
+
+	riskFreeRateHelp := "Annual risk-free rate in percent (sharpe optimizer)"
+	flag.Float64Var(&riskFreeRate, "riskfree", 0.0, riskFreeRateHelp)
+
+	runBacktestHelp := "Backtest the optimizer against holding the default wallet?"
+	flag.BoolVar(&runBacktest, "backtest", false, runBacktestHelp)
+
+	backtestFromHelp := "Backtest start date (YYYY-MM-DD)"
+	flag.StringVar(&backtestFrom, "from", "", backtestFromHelp)
+
+	backtestToHelp := "Backtest end date (YYYY-MM-DD)"
+	flag.StringVar(&backtestTo, "to", "", backtestToHelp)
+
+	backtestPeriodHelp := "Backtest rebalance period in months"
+	flag.IntVar(&backtestPeriod, "period", backtest.DefaultPeriod, backtestPeriodHelp)
+
+	asOfDateHelp := "Compute statistics as of a date, which also ends backtests (YYYY-MM-DD)"
+	flag.StringVar(&asOfDate, "asof", "", asOfDateHelp)
+
+	lookbackHelp := "Lookback window for statistics in months (0 means all history)"
+	flag.IntVar(&lookback, "lookback", 0, lookbackHelp)
+
+	currentWalletHelp := "Name of the current wallet file"
+	flag.StringVar(&currentWallet, "wallet", "default.wallet", currentWalletHelp)
+
+	rebalanceHelp := "Print orders to rebalance the current wallet to the recommended one?"
+	flag.BoolVar(&rebalanceWallet, "rebalance", false, rebalanceHelp)
+
+	minTradeHelp := "Minimum trade size in R$"
+	flag.Float64Var(&minTrade, "mintrade", 100.0, minTradeHelp)
+
+	contributionHelp := "Print orders to invest a new contribution in R$, without selling?"
+	flag.Float64Var(&contribution, "contribute", 0.0, contributionHelp)
+
+	ledgerHelp := "Name of the ledger file to derive the current wallet from"
+	flag.StringVar(&ledgerFilename, "ledger", "", ledgerHelp)
+
+	printHistoryHelp := "Print month-by-month history of the ledger?"
+	flag.BoolVar(&printHistory, "history", false, printHistoryHelp)
+
+	printTaxHelp := "Print monthly capital gains tax report of the ledger?"
+	flag.BoolVar(&printTax, "tax", false, printTaxHelp)
+
+	taxFilenameHelp := "Name of the CSV file to export the tax report"
+	flag.StringVar(&taxFilename, "taxcsv", "", taxFilenameHelp)
+
+	annualYearHelp := "Print annual income tax declaration report of the ledger for a year"
+	flag.IntVar(&annualYear, "irpf", 0, annualYearHelp)
+
+	annualFilenameHelp := "Name of the CSV file to export the annual income tax declaration report"
+	flag.StringVar(&annualFilename, "irpfcsv", "", annualFilenameHelp)
+
+	registryHelp := "Name of the asset registry file"
+	flag.StringVar(&registryFilename, "registry", "default.registry", registryHelp)
+
+	taxonomyHelp := "Name of the class taxonomy file"
+	flag.StringVar(&taxonomyFilename, "taxonomy", "default.taxonomy", taxonomyHelp)
+
+	benchmarkHelp := "Name of the benchmark file to compare assets and wallets against (or " + benchmark.Synthetic + ")"
+	flag.StringVar(&benchmarkName, "benchmark", "", benchmarkHelp)
+
 	flag.Parse()
 }
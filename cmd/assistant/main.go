@@ -25,10 +25,18 @@
 package main
 
 import (
+	"fmt"
 	"os"
+	"portfolio/internal/asset"
+	"portfolio/internal/benchmark"
+	"portfolio/internal/covariance"
 	"portfolio/internal/database"
+	"portfolio/internal/ledger"
+	"portfolio/internal/optimizer"
+	"portfolio/internal/trade"
 	"portfolio/internal/wallet"
 	"portfolio/internal/watchlist"
+	"time"
 )
 
 func main() {
@@ -37,14 +45,96 @@ func main() {
 
 	parseArgs()
 
-	database.Load()
+	// Parse missing-data policy.
+	policy, err := asset.GetPolicy(missingPolicy)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	// Parse resampling method of daily prices.
+	resampling, err := asset.GetResampling(resamplingName)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	// Parse weights of risk measures.
+	measures := newRiskWeights()
+
+	if err = database.Load(registryFilename, taxonomyFilename); err != nil {
+		panic(err.Error())
+	}
+
+	// Set missing-data policy and resampling method of daily prices.
+	for _, a := range database.Assets() {
+		if policy != asset.Skip {
+			a.SetPolicy(policy)
+		}
+		if resampling != asset.MonthEnd {
+			a.SetResampling(resampling)
+		}
+	}
 
 	// Load watchlist.
 	watchlist := watchlist.New()
 	watchlist.Load("default.watchlist")
 
-	// Load default wallet.
-	if myWallet, err = wallet.MyWallet(); err != nil {
+	// Reference date.
+	date := time.Now()
+	if asOfDate != "" {
+		if date, err = time.Parse("2006-01-02", asOfDate); err != nil {
+			panic(err.Error())
+		}
+	}
+
+	// Rewind watchlist.
+	if !runBacktest && (asOfDate != "" || lookback > 0) {
+		watchlist = watchlist.AsOf(date, lookback)
+	}
+
+	// Print correlation matrix.
+	if printCorrelation {
+		matrix, err := covariance.Estimate(watchlist.Assets())
+		if err != nil {
+			panic(err.Error())
+		}
+		matrix.Write(os.Stdout)
+	}
+
+	// Load benchmark.
+	var bench *benchmark.Benchmark
+	if benchmarkName != "" {
+		if bench, err = benchmark.Load(benchmarkName, database.Assets()); err != nil {
+			panic(err.Error())
+		}
+	}
+
+	// Load current wallet.
+	if ledgerFilename != "" {
+		myLedger, err := ledger.Read(ledgerFilename)
+		if err != nil {
+			panic(err.Error())
+		}
+		if printHistory {
+			myLedger.WriteHistory(os.Stdout, date)
+		}
+		if printTax || taxFilename != "" {
+			if err = TaxRun(myLedger); err != nil {
+				panic(err.Error())
+			}
+		}
+		if annualYear > 0 || annualFilename != "" {
+			year := annualYear
+			if year == 0 {
+				year = date.Year() - 1
+			}
+			if err = AnnualRun(myLedger, year); err != nil {
+				panic(err.Error())
+			}
+		}
+		if myWallet, err = myLedger.Replay(date); err != nil {
+			panic(err.Error())
+		}
+	} else if myWallet, err = wallet.Read(currentWallet); err != nil {
 		panic(err.Error())
 	}
 
@@ -53,18 +143,109 @@ func main() {
 		myWallet.Write(os.Stdout)
 	}
 	if printStats {
-		myWallet.PrintStats(os.Stdout)
+		myWallet.PrintStats(os.Stdout, measures)
+	}
+	if printIndex {
+		if err = myWallet.WriteIndex(os.Stdout); err != nil {
+			fmt.Println(err.Error())
+		}
+	}
+
+	// Build objective function.
+	weights := optimizer.Weights{
+		Cost:        float32(costWeight),
+		Performance: float32(perfWeight),
+		Risk:        float32(riskWeight),
+		Data:        float32(dataWeight),
+	}
+	objective, err := optimizer.NewObjective(objectiveName, weights)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	// Load constraints.
+	var constraints *optimizer.Constraints
+	if constraintsFilename != "" {
+		if constraints, err = optimizer.ReadConstraints(constraintsFilename); err != nil {
+			panic(err.Error())
+		}
+		constraints.ResolveLocks(myWallet.Allocation())
 	}
 
 	// Run assistant.
-	newWallet := AssistantRun(watchlist, false)
+	assistant, err := newOptimizer(false)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	// Run backtest.
+	if runBacktest {
+		if bench != nil {
+			BenchmarkRun(bench, watchlist.Assets(), myWallet)
+		}
+		if err = BacktestRun(watchlist, assistant, objective, constraints, myWallet); err != nil {
+			panic(err.Error())
+		}
+		return
+	}
+
+	newWallet, result, err := AssistantRun(watchlist, assistant, objective, constraints)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	// Print and export Pareto front.
+	if result.Front != nil {
+		result.Front.Write(os.Stdout)
+		if frontFilename != "" {
+			if err = result.Front.Persist(frontFilename); err != nil {
+				panic(err.Error())
+			}
+		}
+	}
 
 	// Print info on recommended wallet.
 	if printWallet {
 		newWallet.Write(os.Stdout)
 	}
 	if printStats {
-		newWallet.PrintStats(os.Stdout)
+		newWallet.PrintStats(os.Stdout, measures)
+		if result.Volatility > 0.0 {
+			fmt.Printf("  %-15s %5.2f %%\n\n", "Exp. Return", 100*result.ExpectedReturn)
+		}
+	}
+	if printIndex {
+		if err = newWallet.WriteIndex(os.Stdout); err != nil {
+			fmt.Println(err.Error())
+		}
+	}
+
+	// Print performance relative to benchmark.
+	if bench != nil {
+		BenchmarkRun(bench, watchlist.Assets(), myWallet, newWallet)
+	}
+
+	// Print rebalance orders.
+	if rebalanceWallet {
+		if !myWallet.HasHoldings() && myWallet.Cash() <= 0.0 {
+			// Wallet given only as percentages.
+			trade.WriteWeightChanges(os.Stdout, trade.Reweight(myWallet, newWallet))
+		} else {
+			orders, cash, err := trade.Rebalance(myWallet, newWallet, float32(minTrade))
+			if err != nil {
+				panic(err.Error())
+			}
+			trade.WriteOrders(os.Stdout, orders, cash)
+		}
+	}
+
+	// Print contribution orders.
+	if contribution > 0.0 {
+		orders, cash, err := trade.Contribute(myWallet, newWallet, float32(contribution), float32(minTrade))
+		if err != nil {
+			panic(err.Error())
+		}
+		trade.WriteOrders(os.Stdout, orders, cash)
 	}
 
 	// Save to a file.
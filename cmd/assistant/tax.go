@@ -0,0 +1,69 @@
+/*
+ * MIT License
+ *
+ * Copyright(c) 2020 Pedro Henrique Penna <pedrohenriquepenna@gmail.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package main
+
+import (
+	"os"
+	"portfolio/internal/ledger"
+	"portfolio/internal/tax"
+)
+
+// Computes the capital gains tax of the operations in a ledger.
+func TaxRun(myLedger *ledger.Ledger) error {
+
+	report, err := tax.Compute(myLedger.Transactions())
+	if err != nil {
+		return err
+	}
+
+	if printTax {
+		report.Write(os.Stdout)
+	}
+
+	if taxFilename != "" {
+		return report.Persist(taxFilename)
+	}
+
+	return nil
+}
+
+// Computes the annual income tax declaration report of a ledger.
+func AnnualRun(myLedger *ledger.Ledger, year int) error {
+
+	report, err := tax.ComputeAnnual(myLedger.Transactions(), year)
+	if err != nil {
+		return err
+	}
+
+	if annualYear > 0 {
+		report.Write(os.Stdout)
+	}
+
+	if annualFilename != "" {
+		return report.Persist(annualFilename)
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+/*
+ * MIT License
+ *
+ * Copyright(c) 2020 Pedro Henrique Penna <pedrohenriquepenna@gmail.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package main
+
+import (
+	"flag"
+	"strings"
+)
+
+// Command Line Arguments
+var (
+	sourceName       string // Data Source
+	importDaily      bool   // Import daily series?
+	dryRun           bool   // Report changes without writing them?
+	registryFilename string // Asset Registry File Name
+	taxonomyFilename string // Class Taxonomy File Name
+)
+
+// Parses command line arguments.
+func parseArgs() {
+
+	sourceHelp := "Data source (" + strings.Join(sources(), ", ") + ")"
+	flag.StringVar(&sourceName, "source", "cotahist", sourceHelp)
+
+	importDailyHelp := "Import daily series as well?"
+	flag.BoolVar(&importDaily, "daily", false, importDailyHelp)
+
+	dryRunHelp := "Report changes without writing them?"
+	flag.BoolVar(&dryRun, "dryrun", false, dryRunHelp)
+
+	registryHelp := "Name of the asset registry file"
+	flag.StringVar(&registryFilename, "registry", "default.registry", registryHelp)
+
+	taxonomyHelp := "Name of the class taxonomy file"
+	flag.StringVar(&taxonomyFilename, "taxonomy", "default.taxonomy", taxonomyHelp)
+
+	flag.Parse()
+}
@@ -0,0 +1,83 @@
+/*
+ * MIT License
+ *
+ * Copyright(c) 2020 Pedro Henrique Penna <pedrohenriquepenna@gmail.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"portfolio/internal/database"
+	"portfolio/internal/importer"
+	"sort"
+)
+
+// Importer of a Data Source
+type importFunc func(args []string) ([]*importer.Change, error)
+
+// Known Data Sources
+var sourcesDB = map[string]importFunc{
+	"cotahist": func(args []string) ([]*importer.Change, error) {
+		return importer.ImportCOTAHIST(args, importDaily, dryRun)
+	},
+	"cvm": func(args []string) ([]*importer.Change, error) {
+		return importer.ImportCVM(args, dryRun)
+	},
+}
+
+// Returns the names of known data sources.
+func sources() []string {
+	names := make([]string, 0, len(sourcesDB))
+
+	for name := range sourcesDB {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func main() {
+
+	parseArgs()
+
+	source, ok := sourcesDB[sourceName]
+	if !ok {
+		panic("unknown data source " + sourceName)
+	}
+
+	if err := database.LoadRegistry(registryFilename, taxonomyFilename); err != nil {
+		panic(err.Error())
+	}
+
+	changes, err := source(flag.Args())
+	if err != nil {
+		panic(err.Error())
+	}
+
+	importer.WriteChanges(os.Stdout, changes)
+	if dryRun {
+		fmt.Println("Dry run: no files were written.")
+	}
+}
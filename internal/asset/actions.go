@@ -0,0 +1,331 @@
+/*
+ * MIT License
+ *
+ * Copyright(c) 2020 Pedro Henrique Penna <pedrohenriquepenna@gmail.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package asset
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kinds of Corporate Actions
+const (
+	Split        = iota // Split (one share becomes several)
+	Grouping            // Reverse Split (several shares become one)
+	Subscription        // Subscription of New Shares
+)
+
+// Known Kinds of Corporate Actions
+var actionsDB = map[string]int{
+	"split":        Split,
+	"grouping":     Grouping,
+	"subscription": Subscription,
+}
+
+// Series Adjusted by Corporate Actions
+const (
+	adjustPrice     = 1 << iota // Share Prices
+	adjustDividends             // Dividends per Share
+	adjustBVPS                  // Book Value per Share
+	adjustShares                // Number of Shares
+	adjustAll       = adjustPrice | adjustDividends | adjustBVPS | adjustShares
+)
+
+// Known Adjusted Series
+var adjustDB = map[string]int{
+	"price":     adjustPrice,
+	"dividends": adjustDividends,
+	"bvps":      adjustBVPS,
+	"shares":    adjustShares,
+}
+
+// Corporate Action
+type action struct {
+	date   time.Time // Ex-Date
+	kind   int       // Kind
+	ratio  float32   // Split or Grouping Factor, or New Shares per Share
+	price  float32   // Subscription Price
+	adjust int       // Adjusted Series
+}
+
+// Parses a corporate action.
+//
+//	<date> split <factor> [series]
+//	<date> grouping <factor> [series]
+//	<date> subscription <ratio> <price> [series]
+//
+// Series are separated by commas (default: all).
+func parseAction(fields []string) (*action, error) {
+	var err error
+
+	a := &action{adjust: adjustAll}
+
+	if len(fields) < 3 {
+		return nil, fmt.Errorf("missing fields")
+	}
+
+	if a.date, err = time.Parse("2006-01-02", fields[0]); err != nil {
+		return nil, fmt.Errorf("invalid date %s", fields[0])
+	}
+
+	kind, ok := actionsDB[fields[1]]
+	if !ok {
+		return nil, fmt.Errorf("unknown corporate action %s", fields[1])
+	}
+	a.kind = kind
+
+	ratio, err := strconv.ParseFloat(fields[2], 32)
+	if err != nil || ratio <= 0.0 {
+		return nil, fmt.Errorf("invalid ratio %s", fields[2])
+	}
+	a.ratio = float32(ratio)
+
+	rest := fields[3:]
+	if a.kind == Subscription {
+		if len(rest) == 0 {
+			return nil, fmt.Errorf("missing subscription price")
+		}
+		price, err := strconv.ParseFloat(rest[0], 32)
+		if err != nil || price < 0.0 {
+			return nil, fmt.Errorf("invalid price %s", rest[0])
+		}
+		a.price = float32(price)
+		rest = rest[1:]
+	}
+
+	if len(rest) > 1 {
+		return nil, fmt.Errorf("too many fields")
+	} else if len(rest) == 1 {
+		a.adjust = 0
+		for _, name := range strings.Split(rest[0], ",") {
+			series, ok := adjustDB[name]
+			if !ok {
+				return nil, fmt.Errorf("unknown series %s", name)
+			}
+			a.adjust |= series
+		}
+	}
+
+	return a, nil
+}
+
+// Reads corporate actions from a file, sorted by date.
+func readActions(filename string) ([]*action, error) {
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	actions := make([]*action, 0)
+
+	scanner := bufio.NewScanner(file)
+	for lineno := 1; scanner.Scan(); lineno++ {
+		line := scanner.Text()
+
+		// Skip comments.
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+
+		fields := strings.Fields(line)
+
+		// Skip blank lines.
+		if len(fields) == 0 {
+			continue
+		}
+
+		a, err := parseAction(fields)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %s", filename, lineno, err.Error())
+		}
+		actions = append(actions, a)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(actions, func(i, j int) bool { return actions[i].date.Before(actions[j].date) })
+
+	return actions, nil
+}
+
+// Reads the corporate actions of the target asset from a file, and recomputes
+// its adjusted series and statistics.
+func (a *Asset) ReadActions(filename string) error {
+
+	actions, err := readActions(filename)
+	if err != nil {
+		return err
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.hist.actions = actions
+	a.hist.adjust()
+	a.stats = computeStatistics(a.hist)
+	a.snapshots = nil
+
+	return nil
+}
+
+// Returns the factor of the number of shares held in the target asset from a
+// date up to another one, due to the splits and groupings with ex-dates after
+// the first date and up to the second one. Subscriptions are purchases, so
+// they do not change the shares already held.
+func (a *Asset) SharesFactor(from, to time.Time) float32 {
+	factor := float32(1.0)
+
+	for _, action := range a.hist.actions {
+		if !action.date.After(from) || action.date.After(to) {
+			continue
+		}
+
+		switch action.kind {
+		case Split:
+			factor *= action.ratio
+		case Grouping:
+			factor /= action.ratio
+		}
+	}
+
+	return factor
+}
+
+/*============================================================================*
+ * Adjustment                                                                 *
+ *============================================================================*/
+
+// Adjustment Factors of Records before a Corporate Action
+type factors struct {
+	perShare float32 // Factor of Per-Share Values
+	shares   float32 // Factor of Numbers of Shares
+}
+
+// Computes the adjustment factors of a corporate action, given the last raw
+// share price before it.
+func (a *action) factors(price float32) factors {
+	switch a.kind {
+	case Split:
+		return factors{1.0 / a.ratio, a.ratio}
+	case Grouping:
+		return factors{a.ratio, 1.0 / a.ratio}
+
+	// Theoretical ex-rights price. New shares are actual issuance, so the
+	// number of shares of the fund is not adjusted, unlike traded quantities.
+	case Subscription:
+		if price <= 0.0 {
+			return factors{1.0, 1.0}
+		}
+		return factors{(price + a.ratio*a.price) / ((1.0 + a.ratio) * price), 1.0}
+	}
+
+	return factors{1.0, 1.0}
+}
+
+// Returns the factor of a series, if it is adjusted by a corporate action.
+func (a *action) factor(f factors, series int) float32 {
+	if a.adjust&series == 0 {
+		return 1.0
+	}
+	if series == adjustShares {
+		return f.shares
+	}
+
+	return f.perShare
+}
+
+// Rebuilds the adjusted records of the target history from its raw records
+// and corporate actions. Records before the ex-date of an action are
+// adjusted backwards, so that the latest records match the raw ones.
+func (hist *AssetHistory) adjust() {
+
+	// Nothing to adjust.
+	if len(hist.actions) == 0 {
+		hist.records = hist.raw
+		hist.daily = hist.rawDaily
+		return
+	}
+
+	hist.records = make([]*AssetRecord, len(hist.raw))
+	for t, record := range hist.raw {
+		adjusted := *record
+		hist.records[t] = &adjusted
+	}
+
+	hist.daily = make([]*DailyRecord, len(hist.rawDaily))
+	for t, record := range hist.rawDaily {
+		adjusted := *record
+		hist.daily[t] = &adjusted
+	}
+
+	for _, a := range hist.actions {
+
+		// Last raw price before the action.
+		var price float32
+		for _, record := range hist.raw {
+			if record.date.Before(a.date) {
+				price = record.sharePrice
+			}
+		}
+		for _, record := range hist.rawDaily {
+			if record.date.Before(a.date) {
+				price = record.close
+			}
+		}
+		f := a.factors(price)
+
+		for _, record := range hist.records {
+			if !record.date.Before(a.date) {
+				break
+			}
+			record.sharePrice *= a.factor(f, adjustPrice)
+			record.dividends *= a.factor(f, adjustDividends)
+			record.bvps *= a.factor(f, adjustBVPS)
+			record.numShares = int(float32(record.numShares)*a.factor(f, adjustShares) + 0.5)
+		}
+
+		for _, record := range hist.daily {
+			if !record.date.Before(a.date) {
+				break
+			}
+			// Traded quantities are adjusted inversely to prices, so that
+			// financial volumes are kept.
+			k := a.factor(f, adjustPrice)
+			record.open *= k
+			record.high *= k
+			record.low *= k
+			record.close *= k
+			record.quantity = int64(float64(record.quantity)/float64(k) + 0.5)
+		}
+	}
+}
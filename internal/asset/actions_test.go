@@ -0,0 +1,134 @@
+/*
+ * MIT License
+ *
+ * Copyright(c) 2020 Pedro Henrique Penna <pedrohenriquepenna@gmail.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package asset
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Returns a raw history of three months with constant values, and a daily
+// record in the middle of each month.
+func constantHistory() *AssetHistory {
+	hist := &AssetHistory{}
+
+	for month := time.January; month <= time.March; month++ {
+		record := &AssetRecord{}
+		record.date = time.Date(2020, month+1, 0, 0, 0, 0, 0, time.UTC)
+		record.sharePrice = 100.0
+		record.dividends = 1.0
+		record.bvps = 90.0
+		record.numShares = 1000
+		hist.raw = append(hist.raw, record)
+
+		daily := &DailyRecord{}
+		daily.date = time.Date(2020, month, 15, 0, 0, 0, 0, time.UTC)
+		daily.close = 100.0
+		daily.volume = 100000.0
+		daily.quantity = 1000
+		hist.rawDaily = append(hist.rawDaily, daily)
+	}
+	hist.startDate = hist.raw[0].date
+	hist.endDate = hist.raw[len(hist.raw)-1].date
+
+	return hist
+}
+
+func TestAdjust(t *testing.T) {
+	tests := []struct {
+		action    string  // Corporate Action
+		price     float32 // Expected Share Price before the Action
+		dividends float32 // Expected Dividends before the Action
+		bvps      float32 // Expected Book Value per Share before the Action
+		shares    int     // Expected Number of Shares before the Action
+		quantity  int64   // Expected Traded Quantity before the Action
+	}{
+		{"2020-03-01 split 10", 10.0, 0.1, 9.0, 10000, 10000},
+		{"2020-03-01 grouping 10", 1000.0, 10.0, 900.0, 100, 100},
+		{"2020-03-01 subscription 0.25 80", 96.0, 0.96, 86.4, 1000, 1042}, // (100 + 0.25*80) / (1.25*100)
+		{"2020-03-01 split 2 price,shares", 50.0, 1.0, 90.0, 2000, 2000},
+		{"2020-03-01 split 2 shares", 100.0, 1.0, 90.0, 2000, 1000},
+	}
+
+	near := func(x, y float32) bool {
+		return math.Abs(float64(x-y)) < 1e-4
+	}
+
+	for _, test := range tests {
+		a, err := parseAction(strings.Fields(test.action))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		hist := constantHistory()
+		hist.actions = []*action{a}
+		hist.adjust()
+
+		for i, record := range hist.records {
+			price, dividends, bvps, shares := test.price, test.dividends, test.bvps, test.shares
+
+			// Records from the ex-date on match the raw ones.
+			if !record.date.Before(a.date) {
+				price, dividends, bvps, shares = 100.0, 1.0, 90.0, 1000
+			}
+
+			if !near(record.sharePrice, price) || !near(record.dividends, dividends) ||
+				!near(record.bvps, bvps) || record.numShares != shares {
+				t.Errorf("%s: %s: got %.4f %.4f %.4f %d, want %.4f %.4f %.4f %d",
+					test.action, record.date.Format("2006-01"),
+					record.sharePrice, record.dividends, record.bvps, record.numShares,
+					price, dividends, bvps, shares)
+			}
+
+			if hist.raw[i].sharePrice != 100.0 || hist.raw[i].numShares != 1000 {
+				t.Errorf("%s: raw record %s was modified", test.action, hist.raw[i].date.Format("2006-01"))
+			}
+		}
+
+		// VWAP matches adjusted prices, since volumes are kept.
+		vwap := hist.resample(VWAP)
+		for i, record := range hist.daily {
+			price, quantity := test.price, test.quantity
+			if !record.date.Before(a.date) {
+				price, quantity = 100.0, 1000
+			}
+
+			if !near(record.close, price) || record.quantity != quantity || record.volume != 100000.0 {
+				t.Errorf("%s: %s: got daily %.4f x %d (volume %.2f), want %.4f x %d",
+					test.action, record.date.Format("2006-01-02"),
+					record.close, record.quantity, record.volume, price, quantity)
+			}
+			if got := vwap[monthKey(record.date)]; math.Abs(float64(got-price)) > 0.001*float64(price) {
+				t.Errorf("%s: %s: got VWAP %.4f, want %.4f", test.action, record.date.Format("2006-01"), got, price)
+			}
+
+			if hist.rawDaily[i].close != 100.0 || hist.rawDaily[i].quantity != 1000 {
+				t.Errorf("%s: raw daily record %s was modified", test.action, hist.rawDaily[i].date.Format("2006-01-02"))
+			}
+		}
+	}
+}
@@ -27,6 +27,8 @@ package asset
 import (
 	"fmt"
 	"os"
+	"sync"
+	"time"
 )
 
 // Asset
@@ -36,6 +38,9 @@ type Asset struct {
 	hist   *AssetHistory    // Historical Data
 	stats  *AssetStatistics // Statistics
 	class  int              // Class
+
+	mutex     sync.Mutex             // Lock for Snapshots
+	snapshots map[snapshotKey]*Asset // Cached Snapshots
 }
 
 /*============================================================================*
@@ -43,18 +48,85 @@ type Asset struct {
  *============================================================================*/
 
 // Reads an asset from a file.
-func Read(id int, ticker, filename string, class int) *Asset {
-	a := &Asset{}
+func Read(id int, ticker, filename string, class int) (*Asset, error) {
+	var err error
 
-	// TODO: assert arguments.
+	a := &Asset{}
 
 	a.id = id
 	a.ticker = ticker
 	a.class = class
-	a.hist = readHistory(filename)
+	if a.hist, err = readHistory(filename); err != nil {
+		return nil, err
+	}
 	a.stats = computeStatistics(a.hist)
 
-	return a
+	return a, nil
+}
+
+/*============================================================================*
+ * AsOf()                                                                     *
+ *============================================================================*/
+
+// Minimum number of records to compute statistics.
+const minRecords = 2
+
+// Snapshot Key
+type snapshotKey struct {
+	month    int // Month
+	lookback int // Lookback Window
+}
+
+// Returns a view of the target asset as of a given date, with statistics
+// computed only from the records available up to that date. It returns nil if
+// there is not enough history up to that date.
+func (a *Asset) AsOf(date time.Time) *Asset {
+	return a.AsOfWindow(date, 0)
+}
+
+// Returns a view of the target asset as of a given date, with statistics
+// computed only from the records of the last lookback months up to that date
+// (zero means the whole history). It returns nil if there is not enough
+// history in that window. Views share records with the target asset and are
+// cached, so computing many snapshots is cheap.
+func (a *Asset) AsOfWindow(date time.Time, lookback int) *Asset {
+	key := snapshotKey{monthKey(date), lookback}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if view, ok := a.snapshots[key]; ok {
+		return view
+	}
+
+	var view *Asset
+	hist := a.hist.window(date, lookback)
+	if hist != nil && len(hist.records) >= minRecords {
+		view = &Asset{}
+		view.id = a.id
+		view.ticker = a.ticker
+		view.class = a.class
+		view.hist = hist
+		view.stats = computeStatistics(hist)
+	}
+
+	if a.snapshots == nil {
+		a.snapshots = make(map[snapshotKey]*Asset)
+	}
+	a.snapshots[key] = view
+
+	return view
+}
+
+// Returns views of the target asset as of several dates (see AsOfWindow()).
+func (a *Asset) Snapshots(dates []time.Time, lookback int) []*Asset {
+	views := make([]*Asset, len(dates))
+
+	for i, date := range dates {
+		views[i] = a.AsOfWindow(date, lookback)
+	}
+
+	return views
 }
 
 /*============================================================================*
@@ -79,28 +151,99 @@ func (a *Asset) Ticker() string { return a.ticker }
 // Returns the class of the target asset.
 func (a *Asset) Class() int { return a.class }
 
+// Returns the date of the first record of the target asset.
+func (a *Asset) StartDate() time.Time { return a.hist.startDate }
+
+// Returns the date of the last record of the target asset.
+func (a *Asset) EndDate() time.Time { return a.hist.endDate }
+
+// Returns the number of records of the target asset.
+func (a *Asset) NumRecords() int { return len(a.hist.records) }
+
+// Returns the last share price of the target asset.
+func (a *Asset) LastSharePrice() float32 { return a.stats.lastSharePrice }
+
+// Returns the raw share price of the target asset in the month of a given
+// date, as quoted at that time (not adjusted for later corporate actions).
+func (a *Asset) RawSharePriceAt(date time.Time) (float32, bool) {
+	record := a.hist.rawRecordAt(date)
+	if record == nil || record.sharePrice <= 0.0 {
+		return 0.0, false
+	}
+
+	return record.sharePrice, true
+}
+
+// Returns the share price of the target asset in the month of a given date,
+// adjusted for later corporate actions.
+func (a *Asset) SharePriceAt(date time.Time) (float32, bool) {
+	record := a.hist.recordAt(date)
+	if record == nil || record.sharePrice <= 0.0 {
+		return 0.0, false
+	}
+
+	return record.sharePrice, true
+}
+
+// Returns the dividends per share paid by the target asset in the month of a
+// given date, adjusted for later corporate actions.
+func (a *Asset) DividendsAt(date time.Time) float32 {
+	record := a.hist.recordAt(date)
+	if record == nil {
+		return 0.0
+	}
+
+	return record.dividends
+}
+
+// Returns the market capitalization of the target asset in the month of a
+// given date.
+func (a *Asset) MarketCapAt(date time.Time) (float32, bool) {
+	record := a.hist.recordAt(date)
+	if record == nil || !record.has(fieldMarketCap) || record.marketCap <= 0.0 {
+		return 0.0, false
+	}
+
+	return record.marketCap, true
+}
+
+// Returns the fraction of observed values in the data of the target asset.
+func (a *Asset) Completeness() float32 { return a.stats.completeness }
+
+// Returns the annualized price volatility of the target asset, computed from
+// daily closes if they cover its history, or from monthly prices otherwise.
+func (a *Asset) Volatility() float32 { return a.stats.volatility }
+
+// Returns the maximum drawdown of the price of the target asset, computed
+// from daily closes if they cover its history, or from monthly prices
+// otherwise.
+func (a *Asset) MaxDrawdown() float32 { return a.stats.maxDrawdown }
+
+// Returns the number of daily records of the target asset.
+func (a *Asset) NumDailyRecords() int { return len(a.hist.daily) }
+
 // Returns the performance of the target asset.
 func (a *Asset) Performance() float32 {
 	return a.stats.aagrSharePrice + a.stats.emaDY
 }
 
-// Returns the risk of the target asset.
-func (a *Asset) Risk() float32 {
-	return 0
-}
-
 // Returns the valuation of the target asset.
 func (a *Asset) Cost() float32 {
 	var cost float32
 	var div float32
 
-	div = 2.0
+	div = 1.0
 	cost += -normalize(a.stats.lastSharePrice, a.stats.emaSharePrice)
-	cost += -normalize(a.stats.lastPB, a.stats.avgPB)
+
+	// No book value data.
+	if a.stats.avgPB > 0.0 {
+		cost += -normalize(a.stats.lastPB, a.stats.avgPB)
+		div++
+	}
 
 	if a.stats.avgEquityGLA > 0.1 {
 		cost += -normalize(a.stats.lastEquityGLA, a.stats.avgEquityGLA)
-		div = 3.0
+		div++
 	}
 
 	return cost / div
@@ -120,5 +263,13 @@ func (a *Asset) Write(file *os.File) {
 	fmt.Fprintf(file, "    Avg. P/B   %.2f\n", a.stats.avgPB)
 	fmt.Fprintf(file, "    Avg. DY    %.2f\n", a.stats.avgDY)
 	fmt.Fprintf(file, "    EMA  DY    %.2f\n", a.stats.emaDY)
+	fmt.Fprintf(file, "    Volatility %.2f\n", a.stats.volatility)
+	fmt.Fprintf(file, "    Drawdown   %.2f\n", a.stats.maxDrawdown)
+	fmt.Fprintf(file, "    TR Vol.    %.2f\n", a.stats.risk[RiskVolatility])
+	fmt.Fprintf(file, "    Downside   %.2f\n", a.stats.risk[RiskDownside])
+	fmt.Fprintf(file, "    Div. Var.  %.2f\n", a.stats.risk[RiskDividends])
+	fmt.Fprintf(file, "    Def. Trend %.2f\n", a.stats.risk[RiskDefault])
+	fmt.Fprintf(file, "    Risk       %.2f\n", a.Risk(DefaultRiskWeights))
+	fmt.Fprintf(file, "    Data       %.2f\n", a.stats.completeness)
 	fmt.Fprintf(file, "\n")
 }
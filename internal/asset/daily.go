@@ -0,0 +1,377 @@
+/*
+ * MIT License
+ *
+ * Copyright(c) 2020 Pedro Henrique Penna <pedrohenriquepenna@gmail.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package asset
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Daily Record of an Asset
+type DailyRecord struct {
+	date     time.Time // Date
+	open     float32   // Open Price
+	high     float32   // High Price
+	low      float32   // Low Price
+	close    float32   // Close Price
+	volume   float64   // Financial Volume (zero if unknown)
+	quantity int64     // Number of Shares Traded (zero if unknown)
+	trades   int       // Number of Trades (zero if unknown)
+}
+
+// Resampling Method of Daily Prices
+type Resampling int
+
+// Resampling Methods
+const (
+	MonthEnd       Resampling = iota // Last Close of the Month
+	MonthlyAverage                   // Average Close of the Month
+	VWAP                             // Volume-Weighted Average Price of the Month
+)
+
+// Known Resampling Methods
+var resamplingsDB = map[string]Resampling{
+	"monthend": MonthEnd,
+	"average":  MonthlyAverage,
+	"vwap":     VWAP,
+}
+
+// Number of Trading Days in a Year
+const tradingDays = 252
+
+// Minimum number of daily records to compute daily statistics.
+const minDailyRecords = 20
+
+// Sets the resampling method of daily prices used to compute the statistics of
+// the target asset, and recomputes them.
+func (a *Asset) SetResampling(method Resampling) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.hist.resampling = method
+	a.stats = computeStatistics(a.hist)
+	a.snapshots = nil
+}
+
+// Returns the resampling method of daily prices given its name.
+func GetResampling(name string) (Resampling, error) {
+	method, ok := resamplingsDB[name]
+	if !ok {
+		return MonthEnd, fmt.Errorf("unknown resampling method " + name)
+	}
+
+	return method, nil
+}
+
+// Returns the names of known resampling methods.
+func Resamplings() []string {
+	names := make([]string, 0, len(resamplingsDB))
+
+	for name := range resamplingsDB {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Returns the key of the month of a date.
+func monthKey(date time.Time) int {
+	return date.Year()*12 + int(date.Month()) - 1
+}
+
+/*============================================================================*
+ * readDaily()                                                                *
+ *============================================================================*/
+
+// Parses a daily record, given the column of each field. Columns other than
+// the date and the close price are optional.
+func readDailyRecord(cells []string, columns map[string]int) (*DailyRecord, error) {
+	var err error
+
+	record := &DailyRecord{}
+
+	value := func(name string) (string, bool) {
+		i, ok := columns[name]
+		if !ok {
+			return "", false
+		}
+		v := strings.TrimSpace(cells[i])
+		return v, v != "" && !strings.EqualFold(v, MissingValue)
+	}
+
+	parse := func(name string, x *float32) error {
+		if v, ok := value(name); ok {
+			f, err := strconv.ParseFloat(v, 32)
+			if err != nil || f < 0.0 {
+				return fmt.Errorf("%s: invalid value %s", name, v)
+			}
+			*x = float32(f)
+		}
+		return nil
+	}
+
+	v, _ := value("date")
+	if record.date, err = time.Parse("2006-01-02", v); err != nil {
+		return nil, fmt.Errorf("invalid date %s", v)
+	}
+
+	if err = parse("close", &record.close); err != nil {
+		return nil, err
+	}
+	if record.close <= 0.0 {
+		return nil, fmt.Errorf("missing close")
+	}
+	if err = parse("open", &record.open); err != nil {
+		return nil, err
+	}
+	if err = parse("high", &record.high); err != nil {
+		return nil, err
+	}
+	if err = parse("low", &record.low); err != nil {
+		return nil, err
+	}
+
+	if v, ok := value("volume"); ok {
+		if record.volume, err = strconv.ParseFloat(v, 64); err != nil || record.volume < 0.0 {
+			return nil, fmt.Errorf("volume: invalid value %s", v)
+		}
+	}
+	if v, ok := value("quantity"); ok {
+		if record.quantity, err = strconv.ParseInt(v, 10, 64); err != nil || record.quantity < 0 {
+			return nil, fmt.Errorf("quantity: invalid value %s", v)
+		}
+	}
+	if v, ok := value("trades"); ok {
+		if record.trades, err = strconv.Atoi(v); err != nil || record.trades < 0 {
+			return nil, fmt.Errorf("trades: invalid value %s", v)
+		}
+	}
+
+	return record, nil
+}
+
+// Loads daily history from CSV file. The file must start with a header naming
+// the columns, and records must be sorted by date.
+func readDaily(filename string) ([]*DailyRecord, error) {
+	var columns map[string]int
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	records := make([]*DailyRecord, 0)
+
+	scanner := bufio.NewScanner(file)
+	for lineno := 1; scanner.Scan(); lineno++ {
+		line := scanner.Text()
+
+		// Skip comments.
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+
+		// Skip blank lines.
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		cells := strings.Split(line, ",")
+
+		// Header.
+		if columns == nil {
+			columns = make(map[string]int)
+			for i, name := range cells {
+				columns[strings.ToLower(strings.TrimSpace(name))] = i
+			}
+			for _, name := range []string{"date", "close"} {
+				if _, ok := columns[name]; !ok {
+					return nil, fmt.Errorf("%s:%d: missing column %s", filename, lineno, name)
+				}
+			}
+			continue
+		}
+
+		if len(cells) != len(columns) {
+			return nil, fmt.Errorf("%s:%d: expected %d fields, got %d", filename, lineno, len(columns), len(cells))
+		}
+
+		record, err := readDailyRecord(cells, columns)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %s", filename, lineno, err.Error())
+		}
+
+		// Check dates.
+		if n := len(records); n > 0 && !record.date.After(records[n-1].date) {
+			return nil, fmt.Errorf("%s:%d: %s does not follow %s", filename, lineno,
+				record.date.Format("2006-01-02"), records[n-1].date.Format("2006-01-02"))
+		}
+
+		records = append(records, record)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// Reads the daily history of the target asset from a file, and recomputes its
+// adjusted series and statistics.
+func (a *Asset) ReadDaily(filename string) error {
+
+	daily, err := readDaily(filename)
+	if err != nil {
+		return err
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.hist.rawDaily = daily
+	a.hist.adjust()
+	a.stats = computeStatistics(a.hist)
+	a.snapshots = nil
+
+	return nil
+}
+
+/*============================================================================*
+ * Resampling                                                                 *
+ *============================================================================*/
+
+// Resamples the daily prices of the target history to monthly prices, indexed
+// by month key. VWAP falls back to the monthly average in months without
+// traded quantities.
+func (hist *AssetHistory) resample(method Resampling) map[int]float32 {
+	prices := make(map[int]float32)
+
+	for first := 0; first < len(hist.daily); {
+		var sum, volume float64
+		var quantity int64
+
+		month := monthKey(hist.daily[first].date)
+
+		last := first
+		for ; last < len(hist.daily) && monthKey(hist.daily[last].date) == month; last++ {
+			sum += float64(hist.daily[last].close)
+			volume += hist.daily[last].volume
+			quantity += hist.daily[last].quantity
+		}
+
+		switch {
+		case method == MonthEnd:
+			prices[month] = hist.daily[last-1].close
+		case method == VWAP && quantity > 0:
+			prices[month] = float32(volume / float64(quantity))
+		default:
+			prices[month] = float32(sum / float64(last-first))
+		}
+
+		first = last
+	}
+
+	return prices
+}
+
+// Returns the series of prices of the target history, along with the number of
+// observations per year. Daily closes are used if there are enough of them and
+// they cover all months of the history; otherwise, valid monthly prices are
+// used, so that returns of different frequencies are never mixed.
+func (hist *AssetHistory) priceSeries() ([]float32, int) {
+
+	n := len(hist.daily)
+	if n >= minDailyRecords &&
+		monthKey(hist.daily[0].date) <= monthKey(hist.startDate) &&
+		monthKey(hist.daily[n-1].date) >= monthKey(hist.endDate) {
+		prices := make([]float32, n)
+		for t := range hist.daily {
+			prices[t] = hist.daily[t].close
+		}
+		return prices, tradingDays
+	}
+
+	series, valid := hist.series(fieldSharePrice)
+
+	prices := make([]float32, 0, len(series))
+	for t := range series {
+		if valid[t] && series[t] > 0.0 {
+			prices = append(prices, series[t])
+		}
+	}
+
+	return prices, 12
+}
+
+// Computes the annualized volatility of the returns of a series of prices.
+func volatility(prices []float32, periodsPerYear int) float32 {
+	var mean, variance float64
+
+	if len(prices) < 3 {
+		return 0.0
+	}
+
+	returns := make([]float64, 0, len(prices)-1)
+	for t := 1; t < len(prices); t++ {
+		returns = append(returns, float64(prices[t]/prices[t-1])-1.0)
+	}
+
+	for _, r := range returns {
+		mean += r
+	}
+	mean /= float64(len(returns))
+
+	for _, r := range returns {
+		variance += (r - mean) * (r - mean)
+	}
+	variance /= float64(len(returns) - 1)
+
+	return float32(math.Sqrt(variance * float64(periodsPerYear)))
+}
+
+// Computes the maximum drawdown of a series of prices, as a positive fraction.
+func maxDrawdown(prices []float32) float32 {
+	var peak, drawdown float32
+
+	for _, p := range prices {
+		if p > peak {
+			peak = p
+		}
+		if peak > 0.0 && 1.0-p/peak > drawdown {
+			drawdown = 1.0 - p/peak
+		}
+	}
+
+	return drawdown
+}
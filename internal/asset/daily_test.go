@@ -0,0 +1,58 @@
+/*
+ * MIT License
+ *
+ * Copyright(c) 2020 Pedro Henrique Penna <pedrohenriquepenna@gmail.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package asset
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPriceSeries(t *testing.T) {
+	tests := []struct {
+		from           time.Time // First Day with Daily Prices
+		periodsPerYear int       // Expected Frequency
+	}{
+		{time.Date(2020, time.January, 2, 0, 0, 0, 0, time.UTC), tradingDays}, // Whole history
+		{time.Date(2020, time.March, 2, 0, 0, 0, 0, time.UTC), 12},            // Last months only
+	}
+
+	for _, test := range tests {
+		hist := gappedHistory(Skip)
+		for date := test.from; !date.After(hist.endDate); date = date.AddDate(0, 0, 1) {
+			hist.daily = append(hist.daily, &DailyRecord{date: date, close: 100.0})
+		}
+
+		prices, periodsPerYear := hist.priceSeries()
+		if periodsPerYear != test.periodsPerYear {
+			t.Errorf("daily prices from %s: got %d periods per year, want %d",
+				test.from.Format("2006-01-02"), periodsPerYear, test.periodsPerYear)
+		}
+		for _, p := range prices {
+			if p <= 0.0 {
+				t.Errorf("daily prices from %s: invalid price %.2f in series", test.from.Format("2006-01-02"), p)
+			}
+		}
+	}
+}
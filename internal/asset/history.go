@@ -25,46 +25,206 @@
 package asset
 
 import (
-	"encoding/csv"
+	"bufio"
+	"fmt"
 	"os"
+	"sort"
+	"strings"
 	"time"
 )
 
 // Real Estate History
 type AssetHistory struct {
-	startDate time.Time      // Start
-	endDate   time.Time      // End
-	records   []*AssetRecord // Historical Data
+	startDate  time.Time      // Start
+	endDate    time.Time      // End
+	records    []*AssetRecord // Historical Data (adjusted for corporate actions)
+	daily      []*DailyRecord // Daily Prices (adjusted, sorted by date, may be empty)
+	raw        []*AssetRecord // Raw Historical Data
+	rawDaily   []*DailyRecord // Raw Daily Prices
+	actions    []*action      // Corporate Actions (sorted by date)
+	policy     Policy         // Missing-Data Policy
+	resampling Resampling     // Resampling Method of Daily Prices
 }
 
-// Loads history from CSV file.
-func readHistory(filename string) *AssetHistory {
+// Parses the header of a history file, returning the field of each column.
+func readHeader(line []string) ([]int, error) {
+	columns := make([]int, len(line))
+	seen := make(map[int]bool)
+
+	for i, name := range line {
+		field := lookupField(name)
+		if field >= 0 {
+			if seen[field] {
+				return nil, fmt.Errorf("duplicate column %s", name)
+			}
+			seen[field] = true
+		}
+		columns[i] = field
+	}
+
+	for _, field := range []int{fieldDate, fieldSharePrice} {
+		if !seen[field] {
+			return nil, fmt.Errorf("missing column %s", fieldNames[field])
+		}
+	}
+
+	return columns, nil
+}
+
+// Returns the number of months between two dates.
+func monthsBetween(a, b time.Time) int {
+	return (b.Year()-a.Year())*12 + int(b.Month()) - int(a.Month())
+}
+
+// Loads history from CSV file. The file may start with a header naming the
+// columns, in any order; otherwise, columns follow the order of the fields of
+// a record. Records must be monthly and sorted by date.
+func readHistory(filename string) (*AssetHistory, error) {
+	var columns []int
 
 	hist := &AssetHistory{}
 
 	// Open input file.
 	file, err := os.Open(filename)
 	if err != nil {
-		panic(err.Error)
+		return nil, err
 	}
 	defer file.Close()
 
 	hist.records = make([]*AssetRecord, 0)
 
 	// Read records.
-	reader := csv.NewReader(file)
-	for {
-		line, err := reader.Read()
+	scanner := bufio.NewScanner(file)
+	for lineno := 1; scanner.Scan(); lineno++ {
+		line := scanner.Text()
+
+		// Skip comments.
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+
+		// Skip blank lines.
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		fields := strings.Split(line, ",")
+
+		// Header.
+		if columns == nil {
+			if _, err := time.Parse("2006-01-02", strings.TrimSpace(fields[0])); err != nil {
+				if columns, err = readHeader(fields); err != nil {
+					return nil, fmt.Errorf("%s:%d: %s", filename, lineno, err.Error())
+				}
+				continue
+			}
+
+			columns = make([]int, numFields)
+			for field := range columns {
+				columns[field] = field
+			}
+		}
+
+		record, err := readRecord(fields, columns)
 		if err != nil {
-			break
+			return nil, fmt.Errorf("%s:%d: %s", filename, lineno, err.Error())
+		}
+
+		// Check dates.
+		if n := len(hist.records); n > 0 {
+			prev := hist.records[n-1].date
+			if months := monthsBetween(prev, record.date); months != 1 {
+				return nil, fmt.Errorf("%s:%d: %s does not follow %s by one month",
+					filename, lineno, record.date.Format("2006-01-02"), prev.Format("2006-01-02"))
+			}
 		}
 
-		record := readRecord(line)
 		hist.records = append(hist.records, record)
 	}
 
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(hist.records) == 0 {
+		return nil, fmt.Errorf("%s: no records", filename)
+	}
+
 	hist.startDate = hist.records[0].date
 	hist.endDate = hist.records[len(hist.records)-1].date
+	hist.raw = hist.records
+
+	return hist, nil
+}
+
+// Asserts if two dates lie in the same month.
+func sameMonth(a, b time.Time) bool {
+	return a.Year() == b.Year() && a.Month() == b.Month()
+}
+
+// Returns the number of records up to (and including) the month of a date.
+func (hist *AssetHistory) countUntil(date time.Time) int {
+	return sort.Search(len(hist.records), func(i int) bool {
+		d := hist.records[i].date
+		return d.After(date) && !sameMonth(d, date)
+	})
+}
+
+// Returns the record in the month of a given date, if any.
+func (hist *AssetHistory) recordAt(date time.Time) *AssetRecord {
+	n := hist.countUntil(date)
+
+	if n > 0 && sameMonth(hist.records[n-1].date, date) {
+		return hist.records[n-1]
+	}
+
+	return nil
+}
+
+// Returns the raw record in the month of a given date, if any.
+func (hist *AssetHistory) rawRecordAt(date time.Time) *AssetRecord {
+	n := hist.countUntil(date)
+
+	if n > 0 && sameMonth(hist.raw[n-1].date, date) {
+		return hist.raw[n-1]
+	}
+
+	return nil
+}
+
+// Returns the history up to (and including) the month of a given date,
+// restricted to the last lookback months (zero means no restriction). Records,
+// both monthly and daily, are shared with the target history.
+func (hist *AssetHistory) window(date time.Time, lookback int) *AssetHistory {
+	first := 0
+	last := hist.countUntil(date)
+
+	if lookback > 0 {
+		first = hist.countUntil(date.AddDate(0, -lookback, 1-date.Day()))
+	}
+
+	if first >= last {
+		return nil
+	}
+
+	h := &AssetHistory{}
+	h.records = hist.records[first:last]
+	h.startDate = h.records[0].date
+	h.endDate = h.records[len(h.records)-1].date
+
+	// Daily prices in the months of the window.
+	firstDay := sort.Search(len(hist.daily), func(i int) bool {
+		return monthKey(hist.daily[i].date) >= monthKey(h.startDate)
+	})
+	lastDay := sort.Search(len(hist.daily), func(i int) bool {
+		return monthKey(hist.daily[i].date) > monthKey(h.endDate)
+	})
+	h.daily = hist.daily[firstDay:lastDay]
+	h.raw = hist.raw[first:last]
+	h.rawDaily = hist.rawDaily[firstDay:lastDay]
+	h.actions = hist.actions
+	h.policy = hist.policy
+	h.resampling = hist.resampling
 
-	return hist
+	return h
 }
@@ -0,0 +1,200 @@
+/*
+ * MIT License
+ *
+ * Copyright(c) 2020 Pedro Henrique Penna <pedrohenriquepenna@gmail.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package asset
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Missing-Data Policy
+type Policy int
+
+// Missing-Data Policies
+const (
+	Skip        Policy = iota // Skip Missing Observations
+	ForwardFill               // Carry the Last Observation Forward
+	Interpolate               // Interpolate between Neighbor Observations
+)
+
+// Known Policies
+var policiesDB = map[string]Policy{
+	"skip":        Skip,
+	"ffill":       ForwardFill,
+	"interpolate": Interpolate,
+}
+
+// Sets the missing-data policy used to compute the statistics of the target
+// asset, and recomputes them.
+func (a *Asset) SetPolicy(policy Policy) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.hist.policy = policy
+	a.stats = computeStatistics(a.hist)
+	a.snapshots = nil
+}
+
+// Returns the missing-data policy given its name.
+func GetPolicy(name string) (Policy, error) {
+	policy, ok := policiesDB[name]
+	if !ok {
+		return Skip, fmt.Errorf("unknown missing-data policy " + name)
+	}
+
+	return policy, nil
+}
+
+// Returns the names of known missing-data policies.
+func Policies() []string {
+	names := make([]string, 0, len(policiesDB))
+
+	for name := range policiesDB {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Fields Considered for Data Completeness
+var completenessFields = []int{
+	fieldSharePrice,
+	fieldBVPS,
+	fieldEquity,
+	fieldDividends,
+	fieldGLA,
+}
+
+// Returns the value of a numeric field of the target record.
+func (record *AssetRecord) value(field int) float32 {
+	switch field {
+	case fieldSharePrice:
+		return record.sharePrice
+	case fieldBVPS:
+		return record.bvps
+	case fieldMarketCap:
+		return record.marketCap
+	case fieldEquity:
+		return record.equity
+	case fieldDividends:
+		return record.dividends
+	case fieldFFO:
+		return record.ffo
+	case fieldNumShares:
+		return float32(record.numShares)
+	case fieldDefaultRatio:
+		return record.defaultRatio
+	case fieldGLA:
+		return float32(record.gla)
+	case fieldNumShareHolders:
+		return float32(record.numShareHolders)
+	}
+
+	return 0.0
+}
+
+// Returns the series of a field in the target history, imputing missing
+// observations according to its missing-data policy: skip leaves them out,
+// ffill carries the last observation forward, and interpolate interpolates
+// between neighbor observations and carries the last one forward after it. An
+// observation is valid if it was either observed or imputed, and those before
+// the first one observed are never imputed. Share prices of months with daily
+// prices are resampled from them.
+func (hist *AssetHistory) series(field int) ([]float32, []bool) {
+	var resampled map[int]float32
+
+	policy := hist.policy
+
+	n := len(hist.records)
+	x := make([]float32, n)
+	valid := make([]bool, n)
+
+	if field == fieldSharePrice && len(hist.daily) > 0 {
+		resampled = hist.resample(hist.resampling)
+	}
+
+	last := -1
+	for t, record := range hist.records {
+
+		// Resampled.
+		if price, ok := resampled[monthKey(record.date)]; ok {
+			x[t] = price
+			valid[t] = true
+			last = t
+			continue
+		}
+
+		// Observed.
+		if record.has(field) {
+			x[t] = record.value(field)
+			valid[t] = true
+
+			// Interpolate gap.
+			if policy == Interpolate && last >= 0 {
+				for s := last + 1; s < t; s++ {
+					k := float32(s-last) / float32(t-last)
+					x[s] = x[last] + k*(x[t]-x[last])
+					valid[s] = true
+				}
+			}
+
+			last = t
+			continue
+		}
+
+		// Carry forward.
+		if policy == ForwardFill && last >= 0 {
+			x[t] = x[last]
+			valid[t] = true
+		}
+	}
+
+	// Carry forward trailing observations.
+	if policy == Interpolate && last >= 0 {
+		for t := last + 1; t < n; t++ {
+			x[t] = x[last]
+			valid[t] = true
+		}
+	}
+
+	return x, valid
+}
+
+// Computes the fraction of observed values of the fields considered for data
+// completeness in the target history.
+func (hist *AssetHistory) completeness() float32 {
+	observed := 0
+
+	for _, record := range hist.records {
+		for _, field := range completenessFields {
+			if record.has(field) {
+				observed++
+			}
+		}
+	}
+
+	return float32(observed) / float32(len(hist.records)*len(completenessFields))
+}
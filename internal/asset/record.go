@@ -26,9 +26,53 @@ package asset
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 )
 
+// Fields of a Historical Record
+const (
+	fieldDate            = iota // Date
+	fieldSharePrice             // Price
+	fieldBVPS                   // Book Value per Share
+	fieldMarketCap              // Market Capitalization
+	fieldEquity                 // Equity
+	fieldDividends              // Dividends
+	fieldFFO                    // Funds from Operations
+	fieldNumShares              // Number of Shares
+	fieldDefaultRatio           // Default Ratio
+	fieldGLA                    // Gross Leasable Area
+	fieldNumShareHolders        // Number of Share Holders
+	numFields
+)
+
+// Column Names of Fields (indexed by field)
+var fieldNames = []string{
+	"date",
+	"price",
+	"bvps",
+	"marketcap",
+	"equity",
+	"dividends",
+	"ffo",
+	"shares",
+	"default",
+	"gla",
+	"holders",
+}
+
+// Returns the column names of the fields of a record, in their default order.
+func Columns() []string {
+	columns := make([]string, len(fieldNames))
+	copy(columns, fieldNames)
+
+	return columns
+}
+
+// Value for Missing Data
+const MissingValue = "na"
+
 // Historical Record of an Asset
 type AssetRecord struct {
 	date            time.Time // Date
@@ -42,63 +86,112 @@ type AssetRecord struct {
 	defaultRatio    float32   // Default Ratio
 	gla             int       // Gross Leasable Area
 	numShareHolders int       // Number of Share Holders
+	present         uint32    // Fields with Values (one bit per field)
 }
 
-func readRecord(line []string) *AssetRecord {
-	record := &AssetRecord{}
+// Asserts if a field of the target record has a value.
+func (record *AssetRecord) has(field int) bool {
+	return record.present&(1<<uint(field)) != 0
+}
 
-	// Date
-	record.date, _ = time.Parse("2006-01-02", line[0])
+// Returns the field of a column name, or -1 if the column is unknown.
+func lookupField(name string) int {
+	name = strings.ToLower(strings.TrimSpace(name))
 
-	// Share Price
-	if line[1] != "na" {
-		fmt.Sscanf(line[1], "%f", &record.sharePrice)
+	for field := range fieldNames {
+		if fieldNames[field] == name {
+			return field
+		}
 	}
 
-	// Book Value Price per Share
-	if line[2] != "na" {
-		fmt.Sscanf(line[2], "%f", &record.bvps)
-	}
+	return -1
+}
 
-	// Market Capitalization
-	if line[3] != "na" {
-		fmt.Sscanf(line[3], "%f", &record.marketCap)
+// Parses a real-valued field, which may be negative only if signed.
+func parseReal(value string, x *float32, signed bool) error {
+	f, err := strconv.ParseFloat(value, 32)
+	if err != nil || (f < 0.0 && !signed) {
+		return fmt.Errorf("invalid value %s", value)
 	}
+	*x = float32(f)
 
-	// Equity
-	if line[4] != "na" {
-		fmt.Sscanf(line[4], "%f", &record.equity)
-	}
+	return nil
+}
 
-	// Dividends
-	if line[5] != "na" {
-		fmt.Sscanf(line[5], "%f", &record.dividends)
+// Parses an integer-valued field.
+func parseInteger(value string, x *int) error {
+	i, err := strconv.Atoi(value)
+	if err != nil || i < 0 {
+		return fmt.Errorf("invalid value %s", value)
 	}
+	*x = i
 
-	// Funds from Operations
-	if line[6] != "na" {
-		fmt.Sscanf(line[6], "%f", &record.ffo)
-	}
+	return nil
+}
 
-	// Number of Shares
-	if line[7] != "na" {
-		fmt.Sscanf(line[7], "%d", &record.numShares)
-	}
+// Parses a record, given the field of each column. Values that are empty or
+// "na" are missing. The date and the share price are required.
+func readRecord(line []string, columns []int) (*AssetRecord, error) {
+	var err error
 
-	// Default Ratio
-	if line[8] != "na" {
-		fmt.Sscanf(line[8], "%f", &record.defaultRatio)
-	}
+	record := &AssetRecord{}
 
-	// Gross Leasable Area
-	if line[9] != "na" {
-		fmt.Sscanf(line[9], "%d", &record.gla)
+	if len(line) != len(columns) {
+		return nil, fmt.Errorf("expected %d fields, got %d", len(columns), len(line))
 	}
 
-	// Number of Share Holders
-	if line[10] != "na" {
-		fmt.Sscanf(line[10], "%d", &record.numShareHolders)
+	for i, field := range columns {
+		value := strings.TrimSpace(line[i])
+
+		// Unknown column.
+		if field < 0 {
+			continue
+		}
+
+		// Missing value.
+		if value == "" || strings.EqualFold(value, MissingValue) {
+			if field == fieldDate || field == fieldSharePrice {
+				return nil, fmt.Errorf("missing %s", fieldNames[field])
+			}
+			continue
+		}
+
+		switch field {
+		case fieldDate:
+			record.date, err = time.Parse("2006-01-02", value)
+			if err != nil {
+				err = fmt.Errorf("invalid date %s", value)
+			}
+		case fieldSharePrice:
+			if err = parseReal(value, &record.sharePrice, false); err == nil && record.sharePrice == 0.0 {
+				err = fmt.Errorf("invalid value %s", value)
+			}
+		case fieldBVPS:
+			err = parseReal(value, &record.bvps, false)
+		case fieldMarketCap:
+			err = parseReal(value, &record.marketCap, false)
+		case fieldEquity:
+			err = parseReal(value, &record.equity, false)
+		case fieldDividends:
+			err = parseReal(value, &record.dividends, false)
+		case fieldFFO:
+			err = parseReal(value, &record.ffo, true)
+		case fieldNumShares:
+			err = parseInteger(value, &record.numShares)
+		case fieldDefaultRatio:
+			err = parseReal(value, &record.defaultRatio, true)
+		case fieldGLA:
+			err = parseInteger(value, &record.gla)
+		case fieldNumShareHolders:
+			err = parseInteger(value, &record.numShareHolders)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", fieldNames[field], err.Error())
+		}
+
+		record.present |= 1 << uint(field)
 	}
 
-	return record
+	return record, nil
 }
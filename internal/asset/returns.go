@@ -0,0 +1,124 @@
+/*
+ * MIT License
+ *
+ * Copyright(c) 2020 Pedro Henrique Penna <pedrohenriquepenna@gmail.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package asset
+
+import (
+	"math"
+	"portfolio/internal/utils"
+	"time"
+)
+
+// Computes the monthly total returns (price change plus dividends) of a
+// history, along with the dates on which they were observed. Missing prices
+// and dividends are imputed according to the missing-data policy of the
+// history, and months still missing them are skipped.
+func (hist *AssetHistory) totalReturns() ([]time.Time, []float32) {
+	records := hist.records
+	prices, _ := hist.series(fieldSharePrice)
+	dividends, valid := hist.series(fieldDividends)
+
+	dates := make([]time.Time, 0, len(records))
+	returns := make([]float32, 0, len(records))
+
+	for t := 1; t < len(records); t++ {
+
+		// Missing price or dividends.
+		if prices[t-1] <= 0.0 || prices[t] <= 0.0 || !valid[t] {
+			continue
+		}
+
+		r := (prices[t]+dividends[t])/prices[t-1] - 1.0
+
+		dates = append(dates, records[t].date)
+		returns = append(returns, r)
+	}
+
+	return dates, returns
+}
+
+// Returns the monthly total returns (price change plus dividends) of the
+// target asset, along with the dates on which they were observed.
+func (a *Asset) TotalReturns() ([]time.Time, []float32) {
+	return a.hist.totalReturns()
+}
+
+// Computes the total return index of a history, aligned with its records: it
+// starts at one and grows with monthly total returns, so dividends are
+// reinvested in the asset. Months with a missing price or missing dividends,
+// after imputation, carry the index over.
+func (hist *AssetHistory) totalReturnIndex() []float32 {
+	prices, _ := hist.series(fieldSharePrice)
+	dividends, valid := hist.series(fieldDividends)
+
+	index := make([]float32, len(hist.records))
+	index[0] = 1.0
+
+	for t := 1; t < len(hist.records); t++ {
+		index[t] = index[t-1]
+		if prices[t-1] > 0.0 && prices[t] > 0.0 && valid[t] {
+			index[t] *= (prices[t] + dividends[t]) / prices[t-1]
+		}
+	}
+
+	return index
+}
+
+// Returns the total return index of the target asset (price changes plus
+// reinvested dividends, starting at one), along with its dates.
+func (a *Asset) TotalReturnIndex() ([]time.Time, []float32) {
+	dates := make([]time.Time, len(a.hist.records))
+	index := make([]float32, len(a.hist.records))
+
+	for t, record := range a.hist.records {
+		dates[t] = record.date
+	}
+	copy(index, a.stats.totalReturnIndex)
+
+	return dates, index
+}
+
+// Returns the total return index of the target asset in the month of a given
+// date.
+func (a *Asset) TotalReturnIndexAt(date time.Time) (float32, bool) {
+	n := a.hist.countUntil(date)
+
+	if n > 0 && sameMonth(a.hist.records[n-1].date, date) {
+		return a.stats.totalReturnIndex[n-1], true
+	}
+
+	return 0.0, false
+}
+
+// Returns the annualized total return of the target asset over its history.
+func (a *Asset) AnnualizedTotalReturn() float32 {
+	index := a.stats.totalReturnIndex
+	years := utils.YearFrac(a.hist.startDate, a.hist.endDate)
+
+	if years <= 0.0 || index[len(index)-1] <= 0.0 {
+		return 0.0
+	}
+
+	return float32(math.Pow(float64(index[len(index)-1]), 1.0/float64(years))) - 1.0
+}
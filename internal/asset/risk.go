@@ -0,0 +1,217 @@
+/*
+ * MIT License
+ *
+ * Copyright(c) 2020 Pedro Henrique Penna <pedrohenriquepenna@gmail.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package asset
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Risk Measures
+const (
+	RiskVolatility = iota // Annualized Volatility of Total Returns
+	RiskDrawdown          // Maximum Drawdown
+	RiskDownside          // Annualized Downside Deviation of Total Returns
+	RiskDividends         // Coefficient of Variation of Dividends
+	RiskDefault           // Yearly Increase of the Default Ratio
+	numRiskMeasures
+)
+
+// Known Risk Measures
+var riskMeasuresDB = map[string]int{
+	"volatility": RiskVolatility,
+	"drawdown":   RiskDrawdown,
+	"downside":   RiskDownside,
+	"dividends":  RiskDividends,
+	"default":    RiskDefault,
+}
+
+// Weights of Risk Measures (indexed by measure)
+type RiskWeights [numRiskMeasures]float32
+
+// Default weights of risk measures.
+var DefaultRiskWeights = RiskWeights{1.0, 1.0, 1.0, 1.0, 1.0}
+
+// Parses weights of risk measures given as a comma-separated list of
+// name=weight pairs. Measures that are not listed keep their default weight.
+func ParseRiskWeights(s string) (RiskWeights, error) {
+	w := DefaultRiskWeights
+
+	for _, pair := range strings.Split(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+
+		fields := strings.Split(pair, "=")
+		if len(fields) != 2 {
+			return w, fmt.Errorf("invalid risk weight " + pair)
+		}
+
+		name := strings.TrimSpace(fields[0])
+		measure, ok := riskMeasuresDB[name]
+		if !ok {
+			return w, fmt.Errorf("unknown risk measure " + name)
+		}
+
+		weight, err := strconv.ParseFloat(strings.TrimSpace(fields[1]), 32)
+		if err != nil || weight < 0.0 {
+			return w, fmt.Errorf("invalid weight for risk measure " + name)
+		}
+
+		w[measure] = float32(weight)
+	}
+
+	return w, nil
+}
+
+// Returns the names of known risk measures.
+func RiskMeasures() []string {
+	names := make([]string, 0, len(riskMeasuresDB))
+
+	for name := range riskMeasuresDB {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Computes the annualized standard deviation of monthly returns.
+func returnVolatility(returns []float32) float32 {
+	var mean, variance float64
+
+	if len(returns) < 2 {
+		return 0.0
+	}
+
+	for _, r := range returns {
+		mean += float64(r)
+	}
+	mean /= float64(len(returns))
+
+	for _, r := range returns {
+		variance += (float64(r) - mean) * (float64(r) - mean)
+	}
+	variance /= float64(len(returns) - 1)
+
+	return float32(math.Sqrt(12.0 * variance))
+}
+
+// Computes the annualized downside deviation of monthly returns, that is, the
+// root mean square of negative returns.
+func downsideDeviation(returns []float32) float32 {
+	var sum float64
+
+	if len(returns) == 0 {
+		return 0.0
+	}
+
+	for _, r := range returns {
+		if r < 0.0 {
+			sum += float64(r) * float64(r)
+		}
+	}
+
+	return float32(math.Sqrt(12.0 * sum / float64(len(returns))))
+}
+
+// Computes the coefficient of variation (standard deviation over mean) of
+// the valid values of a series.
+func variation(x []float32, valid []bool) float32 {
+	var n int
+	var mean, variance float64
+
+	for t := range x {
+		if valid[t] {
+			mean += float64(x[t])
+			n++
+		}
+	}
+	if n < 2 || mean <= 0.0 {
+		return 0.0
+	}
+	mean /= float64(n)
+
+	for t := range x {
+		if valid[t] {
+			variance += (float64(x[t]) - mean) * (float64(x[t]) - mean)
+		}
+	}
+	variance /= float64(n - 1)
+
+	return float32(math.Sqrt(variance) / mean)
+}
+
+// Computes the least-squares slope per year of the valid values of a monthly
+// series.
+func trend(x []float32, valid []bool) float32 {
+	var n, sumT, sumX, sumTT, sumTX float64
+
+	for t := range x {
+		if valid[t] {
+			years := float64(t) / 12.0
+			n++
+			sumT += years
+			sumX += float64(x[t])
+			sumTT += years * years
+			sumTX += years * float64(x[t])
+		}
+	}
+
+	if n < 3 || n*sumTT-sumT*sumT <= 0.0 {
+		return 0.0
+	}
+
+	return float32((n*sumTX - sumT*sumX) / (n*sumTT - sumT*sumT))
+}
+
+// Returns the risk of the target asset, as the weighted average of its risk
+// measures. Higher values mean riskier assets.
+func (a *Asset) Risk(weights RiskWeights) float32 {
+	var risk, norm float32
+
+	for measure, weight := range weights {
+		risk += weight * a.stats.risk[measure]
+		norm += weight
+	}
+
+	if norm <= 0.0 {
+		return 0.0
+	}
+
+	return risk / norm
+}
+
+// Returns a risk measure of the target asset.
+func (a *Asset) RiskMeasure(measure int) float32 {
+	if measure < 0 || measure >= numRiskMeasures {
+		return 0.0
+	}
+
+	return a.stats.risk[measure]
+}
@@ -46,6 +46,16 @@ type AssetStatistics struct {
 	// DY Statistics
 	avgDY float32 // Average Dividend Yield
 	emaDY float32 // EMA Dividend Yield
+
+	// Price Risk Statistics
+	volatility  float32 // Annualized Volatility
+	maxDrawdown float32 // Maximum Drawdown
+
+	risk [numRiskMeasures]float32 // Risk Measures (indexed by measure)
+
+	totalReturnIndex []float32 // Total Return Index (aligned with records)
+
+	completeness float32 // Data Completeness
 }
 
 // Compute the EMA of a serie.
@@ -65,60 +75,124 @@ func computeEMA(x []float32, t, n int) float32 {
 	return ema(x, t)
 }
 
-// Computes statistics on dividend yield.
+// Computes statistics on dividend yield. Months without dividend data are
+// handled according to the missing-data policy, and months without a valid
+// share price are skipped.
 func (stats *AssetStatistics) computeDY(hist *AssetHistory) {
 
+	prices, validPrices := hist.series(fieldSharePrice)
+	dividends, valid := hist.series(fieldDividends)
+
 	histDY := make([]float32, 0)
 
 	// Compute average statistics.
-	for _, record := range hist.records {
-		dy := 12.0 * record.dividends / record.sharePrice
+	for t := range prices {
+		if !valid[t] || !validPrices[t] || prices[t] <= 0.0 {
+			continue
+		}
+
+		dy := 12.0 * dividends[t] / prices[t]
 
 		stats.avgDY += dy
 		histDY = append(histDY, dy)
 	}
 
-	stats.emaDY = computeEMA(histDY, len(histDY)-1, 6)
-	stats.avgDY /= float32(len(hist.records))
+	if len(histDY) > 0 {
+		stats.emaDY = computeEMA(histDY, len(histDY)-1, 6)
+		stats.avgDY /= float32(len(histDY))
+	}
 }
 
-// Computes statistics on share price.
+// Computes statistics on share price. Months without a valid share price are
+// skipped, months without book value, equity or GLA data are handled according
+// to the missing-data policy, and ratios are left at zero if no data is
+// available at all.
 func (stats *AssetStatistics) computeSharePrice(hist *AssetHistory) {
+	var numPB, numEquityGLA int
+	var first, last int
 
-	// First and last records.
-	firstRecord := hist.records[0]
-	lastRecord := hist.records[len(hist.records)-1]
+	allPrices, validPrices := hist.series(fieldSharePrice)
+	bvps, validBVPS := hist.series(fieldBVPS)
+	equity, validEquity := hist.series(fieldEquity)
+	gla, validGLA := hist.series(fieldGLA)
 
-	// Last Share Price
-	stats.lastSharePrice = lastRecord.sharePrice
-
-	// Last P/B
-	stats.lastPB = lastRecord.sharePrice / lastRecord.bvps
-
-	// Last Equity GLA
-	stats.lastEquityGLA = lastRecord.equity / float32(lastRecord.gla)
+	prices := make([]float32, 0, len(allPrices))
 
 	// Compute average statistics.
-	histSharePrice := make([]float32, 0)
-	for _, record := range hist.records {
-		stats.avgSharePrice += record.sharePrice
-		stats.avgPB += record.sharePrice / record.bvps
+	for t := range allPrices {
+		if !validPrices[t] || allPrices[t] <= 0.0 {
+			continue
+		}
 
-		if record.gla > 0 {
-			stats.avgEquityGLA += record.equity / float32(record.gla)
+		if len(prices) == 0 {
+			first = t
+		}
+		last = t
+		prices = append(prices, allPrices[t])
+		stats.avgSharePrice += allPrices[t]
+
+		// P/B
+		if validBVPS[t] && bvps[t] > 0 {
+			stats.lastPB = allPrices[t] / bvps[t]
+			stats.avgPB += stats.lastPB
+			numPB++
 		}
 
-		histSharePrice = append(histSharePrice, record.sharePrice)
+		// Equity/GLA
+		if validEquity[t] && validGLA[t] && gla[t] > 0 {
+			stats.lastEquityGLA = equity[t] / gla[t]
+			stats.avgEquityGLA += stats.lastEquityGLA
+			numEquityGLA++
+		}
 	}
-	stats.avgSharePrice /= float32(len(hist.records))
-	stats.avgPB /= float32(len(hist.records))
-	stats.avgEquityGLA /= float32(len(hist.records))
-	stats.emaSharePrice = computeEMA(histSharePrice, len(histSharePrice)-1, 6)
+
+	// No price data.
+	if len(prices) == 0 {
+		return
+	}
+
+	// Last Share Price
+	stats.lastSharePrice = prices[len(prices)-1]
+
+	stats.avgSharePrice /= float32(len(prices))
+	if numPB > 0 {
+		stats.avgPB /= float32(numPB)
+	}
+	if numEquityGLA > 0 {
+		stats.avgEquityGLA /= float32(numEquityGLA)
+	}
+	stats.emaSharePrice = computeEMA(prices, len(prices)-1, 6)
 
 	// Compute average anual growth rate.
-	duration := utils.MonthDiff(hist.startDate, hist.endDate)
-	priceDevelopment := lastRecord.sharePrice/firstRecord.sharePrice - 1
-	stats.aagrSharePrice = 12 * priceDevelopment / float32(duration)
+	duration := utils.YearFrac(hist.records[first].date, hist.records[last].date)
+	if duration > 0.0 {
+		priceDevelopment := prices[len(prices)-1]/prices[0] - 1
+		stats.aagrSharePrice = priceDevelopment / duration
+	}
+}
+
+// Computes statistics on risk. Price risk uses daily prices if available,
+// while the other measures use monthly records, handling missing dividend and
+// default data according to the missing-data policy. Only increases of the
+// default ratio (given in percent) count as risk.
+func (stats *AssetStatistics) computeRisk(hist *AssetHistory) {
+
+	prices, periodsPerYear := hist.priceSeries()
+
+	stats.volatility = volatility(prices, periodsPerYear)
+	stats.maxDrawdown = maxDrawdown(prices)
+
+	_, returns := hist.totalReturns()
+	dividends, validDividends := hist.series(fieldDividends)
+	defaultRatio, validDefault := hist.series(fieldDefaultRatio)
+
+	stats.risk[RiskVolatility] = returnVolatility(returns)
+	stats.risk[RiskDrawdown] = stats.maxDrawdown
+	stats.risk[RiskDownside] = downsideDeviation(returns)
+	stats.risk[RiskDividends] = variation(dividends, validDividends)
+	if slope := trend(defaultRatio, validDefault) / 100.0; slope > 0.0 {
+		stats.risk[RiskDefault] = slope
+	}
 }
 
 // Compute statistics on historical data.
@@ -128,6 +202,9 @@ func computeStatistics(hist *AssetHistory) *AssetStatistics {
 
 	stats.computeSharePrice(hist)
 	stats.computeDY(hist)
+	stats.computeRisk(hist)
+	stats.totalReturnIndex = hist.totalReturnIndex()
+	stats.completeness = hist.completeness()
 
 	return stats
 }
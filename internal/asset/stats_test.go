@@ -0,0 +1,129 @@
+/*
+ * MIT License
+ *
+ * Copyright(c) 2020 Pedro Henrique Penna <pedrohenriquepenna@gmail.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package asset
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// Returns a history whose second record misses the share price.
+func gappedHistory(policy Policy) *AssetHistory {
+	hist := &AssetHistory{}
+
+	for t, price := range []float32{100.0, 0.0, 110.0, 120.0} {
+		record := &AssetRecord{}
+		record.date = time.Date(2020, time.Month(t+2), 0, 0, 0, 0, 0, time.UTC)
+		record.bvps = 100.0
+		record.dividends = 1.0
+		record.present = 1<<uint(fieldDate) | 1<<uint(fieldBVPS) | 1<<uint(fieldDividends)
+		if price > 0.0 {
+			record.sharePrice = price
+			record.present |= 1 << uint(fieldSharePrice)
+		}
+		hist.records = append(hist.records, record)
+	}
+	hist.startDate = hist.records[0].date
+	hist.endDate = hist.records[len(hist.records)-1].date
+	hist.raw = hist.records
+	hist.policy = policy
+
+	return hist
+}
+
+func TestMissingPrices(t *testing.T) {
+	tests := []struct {
+		policy   Policy  // Missing-Data Policy
+		avgPrice float32 // Expected Average Share Price
+	}{
+		{Skip, 110.0},
+		{ForwardFill, 107.5},
+		{Interpolate, 108.75},
+	}
+
+	for _, test := range tests {
+		stats := computeStatistics(gappedHistory(test.policy))
+
+		values := []float32{
+			stats.avgSharePrice, stats.emaSharePrice, stats.aagrSharePrice,
+			stats.avgPB, stats.avgDY, stats.emaDY,
+		}
+		for _, x := range values {
+			if math.IsNaN(float64(x)) || math.IsInf(float64(x), 0) {
+				t.Fatalf("policy %d: non-finite statistic in %v", test.policy, values)
+			}
+		}
+
+		if math.Abs(float64(stats.avgSharePrice-test.avgPrice)) > 1e-4 {
+			t.Errorf("policy %d: got average price %.4f, want %.4f", test.policy, stats.avgSharePrice, test.avgPrice)
+		}
+	}
+}
+
+func TestMissingDividends(t *testing.T) {
+	tests := []struct {
+		policy  Policy    // Missing-Data Policy
+		returns []float32 // Expected Monthly Total Returns
+	}{
+		{Skip, []float32{0.03, 0.01}},
+		{ForwardFill, []float32{0.01, 0.03, 0.01}},
+		{Interpolate, []float32{0.02, 0.03, 0.01}},
+	}
+
+	for _, test := range tests {
+
+		// The second record misses its dividends.
+		hist := gappedHistory(test.policy)
+		for m, dividends := range []float32{1.0, 0.0, 3.0, 1.0} {
+			record := hist.records[m]
+			record.sharePrice = 100.0
+			record.present |= 1 << uint(fieldSharePrice)
+			record.dividends = dividends
+			if dividends == 0.0 {
+				record.present &^= 1 << uint(fieldDividends)
+			}
+		}
+
+		_, returns := hist.totalReturns()
+		if len(returns) != len(test.returns) {
+			t.Errorf("policy %d: got returns %v, want %v", test.policy, returns, test.returns)
+			continue
+		}
+
+		index := float32(1.0)
+		for i := range returns {
+			if math.Abs(float64(returns[i]-test.returns[i])) > 1e-6 {
+				t.Errorf("policy %d: got returns %v, want %v", test.policy, returns, test.returns)
+				break
+			}
+			index *= 1.0 + test.returns[i]
+		}
+
+		if got := hist.totalReturnIndex(); math.Abs(float64(got[len(got)-1]-index)) > 1e-6 {
+			t.Errorf("policy %d: got index %.6f, want %.6f", test.policy, got[len(got)-1], index)
+		}
+	}
+}
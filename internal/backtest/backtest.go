@@ -0,0 +1,310 @@
+/*
+ * MIT License
+ *
+ * Copyright(c) 2020 Pedro Henrique Penna <pedrohenriquepenna@gmail.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package backtest
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"portfolio/internal/asset"
+	"portfolio/internal/optimizer"
+	"time"
+)
+
+// Default Backtest Configuration
+const (
+	DefaultPeriod     = 3  // Rebalance Period (months)
+	DefaultMinHistory = 12 // Minimum History for an Asset to be Eligible (months)
+)
+
+// Backtest
+type Backtest struct {
+	Assets      []*asset.Asset         // Assets
+	Optimizer   optimizer.Optimizer    // Optimizer
+	Objective   optimizer.Objective    // Objective Function
+	Constraints *optimizer.Constraints // Allocation Constraints (optional)
+	RiskWeights asset.RiskWeights      // Weights of Risk Measures of Assets
+	RiskTerms   optimizer.RiskTerms    // Weights of Covariance-Based Risk Terms
+	Benchmark   map[int]float32        // Buy-and-Hold Allocation (indexed by asset ID)
+	Start       time.Time              // Start Date
+	End         time.Time              // End Date
+	Period      int                    // Rebalance Period (months)
+	MinHistory  int                    // Minimum History for an Asset (months)
+	Lookback    int                    // Lookback Window for Statistics (months, zero means all)
+	Seed        int64                  // Seed for the Random Number Generator
+}
+
+// Portfolio under Simulation
+type portfolio struct {
+	values   map[int]float32 // Market Value of Positions (indexed by asset ID)
+	cash     float32         // Cash
+	income   float32         // Cumulative Dividend Income
+	turnover float32         // Cumulative Turnover
+}
+
+// Creates a backtest with default configuration.
+func New(assets []*asset.Asset, opt optimizer.Optimizer, objective optimizer.Objective) *Backtest {
+	bt := &Backtest{}
+
+	bt.Assets = assets
+	bt.Optimizer = opt
+	bt.Objective = objective
+	bt.RiskWeights = asset.DefaultRiskWeights
+	bt.Benchmark = make(map[int]float32)
+	bt.Period = DefaultPeriod
+	bt.MinHistory = DefaultMinHistory
+	bt.Seed = time.Hour.Nanoseconds()
+
+	// Simulate over the whole history by default, as soon as some asset
+	// has enough history.
+	for _, a := range assets {
+		if bt.Start.IsZero() || a.StartDate().Before(bt.Start) {
+			bt.Start = a.StartDate()
+		}
+		if a.EndDate().After(bt.End) {
+			bt.End = a.EndDate()
+		}
+	}
+	bt.Start = bt.Start.AddDate(0, bt.MinHistory-1, 0)
+
+	return bt
+}
+
+/*============================================================================*
+ * Portfolio                                                                  *
+ *============================================================================*/
+
+// Creates an empty portfolio.
+func newPortfolio() *portfolio {
+	p := &portfolio{}
+
+	p.values = make(map[int]float32)
+	p.cash = 1.0
+
+	return p
+}
+
+// Returns the net asset value of a portfolio.
+func (p *portfolio) nav() float32 {
+	nav := p.cash
+
+	for _, value := range p.values {
+		nav += value
+	}
+
+	return nav
+}
+
+// Rebalances a portfolio to a target allocation. Turnover is measured as the
+// fraction of the net asset value that is traded.
+func (p *portfolio) rebalance(allocation map[int]float32) {
+	var traded float32
+
+	nav := p.nav()
+	values := make(map[int]float32)
+
+	for assetID, weight := range allocation {
+		if weight > 0.0 {
+			values[assetID] = weight * nav
+		}
+	}
+
+	for assetID, value := range values {
+		traded += float32(math.Abs(float64(value - p.values[assetID])))
+	}
+	for assetID, value := range p.values {
+		if _, ok := values[assetID]; !ok {
+			traded += value
+		}
+	}
+
+	// Initial investment is not accounted as turnover.
+	if nav > 0.0 && p.cash < nav {
+		p.turnover += traded / (2 * nav)
+	}
+
+	p.values = values
+	p.cash = 0.0
+	for _, value := range values {
+		p.cash -= value
+	}
+	p.cash += nav
+}
+
+// Moves a portfolio one month forward. Dividends are kept as cash.
+func (p *portfolio) step(assets map[int]*asset.Asset, prev, curr time.Time) {
+	for assetID, value := range p.values {
+		a := assets[assetID]
+
+		prevPrice, ok1 := a.SharePriceAt(prev)
+		currPrice, ok2 := a.SharePriceAt(curr)
+
+		// Missing prices: hold value.
+		if !ok1 || !ok2 {
+			continue
+		}
+
+		dividends := value * a.DividendsAt(curr) / prevPrice
+		p.values[assetID] = value * currPrice / prevPrice
+		p.cash += dividends
+		p.income += dividends
+	}
+}
+
+/*============================================================================*
+ * Run()                                                                      *
+ *============================================================================*/
+
+// Returns the last day of the month of a date.
+func monthEnd(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month()+1, 0, 0, 0, 0, 0, time.UTC)
+}
+
+// Optimizes an allocation with the data available at a given date.
+func (bt *Backtest) optimize(date time.Time) (map[int]float32, error) {
+	views := make([]*asset.Asset, 0)
+
+	for _, a := range bt.Assets {
+
+		// Not enough history.
+		if past := a.AsOf(date); past == nil || past.NumRecords() < bt.MinHistory {
+			continue
+		}
+
+		view := a.AsOfWindow(date, bt.Lookback)
+		if view == nil {
+			continue
+		}
+
+		// Not traded at this date.
+		if _, ok := view.SharePriceAt(date); !ok {
+			continue
+		}
+
+		views = append(views, view)
+	}
+
+	if len(views) == 0 {
+		return nil, fmt.Errorf("no eligible assets at " + date.Format("2006-01-02"))
+	}
+
+	rng := rand.New(rand.NewSource(bt.Seed))
+	problem := optimizer.NewProblem(views, bt.Objective, rng)
+	problem.Constraints = bt.Constraints
+	problem.RiskWeights = bt.RiskWeights
+	problem.RiskTerms = bt.RiskTerms
+
+	// Few eligible assets: relax the maximum allocation.
+	if problem.MaxAllocation*float32(len(views)) < 1.0 {
+		problem.MaxAllocation = 1.0 / float32(len(views))
+	}
+
+	result, err := bt.Optimizer.Optimize(problem)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", date.Format("2006-01-02"), err.Error())
+	}
+
+	return result.Allocation, nil
+}
+
+// Builds the buy-and-hold allocation at a given date, renormalizing weights
+// among assets that are traded at that date.
+func (bt *Backtest) benchmarkAt(date time.Time, assets map[int]*asset.Asset) map[int]float32 {
+	var total float32
+	allocation := make(map[int]float32)
+
+	for assetID, weight := range bt.Benchmark {
+		a, ok := assets[assetID]
+		if !ok {
+			continue
+		}
+		if _, ok := a.SharePriceAt(date); ok && weight > 0.0 {
+			allocation[assetID] = weight
+			total += weight
+		}
+	}
+
+	for assetID := range allocation {
+		allocation[assetID] /= total
+	}
+
+	return allocation
+}
+
+// Runs a backtest.
+func (bt *Backtest) Run() (*Report, error) {
+
+	if bt.Period <= 0 {
+		return nil, fmt.Errorf("invalid rebalance period")
+	}
+	if !bt.Start.Before(bt.End) {
+		return nil, fmt.Errorf("invalid backtest interval")
+	}
+
+	assets := make(map[int]*asset.Asset)
+	for _, a := range bt.Assets {
+		assets[a.ID()] = a
+	}
+
+	strategy := newPortfolio()
+	benchmark := newPortfolio()
+
+	report := newReport()
+
+	start := monthEnd(bt.Start)
+	end := monthEnd(bt.End)
+	benchmark.rebalance(bt.benchmarkAt(start, assets))
+
+	month := 0
+	prev := start
+	for date := start; !date.After(end); date = monthEnd(date.AddDate(0, 0, 1)) {
+
+		// Move forward.
+		if month > 0 {
+			strategy.step(assets, prev, date)
+			benchmark.step(assets, prev, date)
+		}
+
+		// Rebalance.
+		if month%bt.Period == 0 {
+			allocation, err := bt.optimize(date)
+			if err != nil {
+				return nil, err
+			}
+
+			turnover := strategy.turnover
+			strategy.rebalance(allocation)
+			report.addRebalance(date, allocation, strategy.turnover-turnover)
+		}
+
+		report.Strategy.add(date, strategy)
+		report.Benchmark.add(date, benchmark)
+
+		prev = date
+		month++
+	}
+
+	return report, nil
+}
@@ -0,0 +1,170 @@
+/*
+ * MIT License
+ *
+ * Copyright(c) 2020 Pedro Henrique Penna <pedrohenriquepenna@gmail.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package backtest
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"portfolio/internal/database"
+	"sort"
+	"time"
+)
+
+// Performance of a Portfolio over Time
+type Performance struct {
+	Dates    []time.Time // Dates
+	NAV      []float32   // Net Asset Value (starts at 1.0)
+	Income   float32     // Cumulative Dividend Income (fraction of initial value)
+	Turnover float32     // Cumulative Turnover
+}
+
+// Rebalance Event
+type Rebalance struct {
+	Date       time.Time       // Date
+	Allocation map[int]float32 // Target Allocation (indexed by asset ID)
+	Turnover   float32         // Turnover
+}
+
+// Backtest Report
+type Report struct {
+	Strategy   *Performance // Performance of the Strategy
+	Benchmark  *Performance // Performance of Buy-and-Hold
+	Rebalances []*Rebalance // Rebalance Events
+}
+
+// Creates an empty report.
+func newReport() *Report {
+	r := &Report{}
+
+	r.Strategy = &Performance{}
+	r.Benchmark = &Performance{}
+	r.Rebalances = make([]*Rebalance, 0)
+
+	return r
+}
+
+// Records a rebalance event.
+func (r *Report) addRebalance(date time.Time, allocation map[int]float32, turnover float32) {
+	r.Rebalances = append(r.Rebalances, &Rebalance{date, allocation, turnover})
+}
+
+/*============================================================================*
+ * Performance                                                                *
+ *============================================================================*/
+
+// Records the state of a portfolio at a given date.
+func (perf *Performance) add(date time.Time, p *portfolio) {
+	perf.Dates = append(perf.Dates, date)
+	perf.NAV = append(perf.NAV, p.nav())
+	perf.Income = p.income
+	perf.Turnover = p.turnover
+}
+
+// Returns the cumulative total return.
+func (perf *Performance) TotalReturn() float32 {
+	if len(perf.NAV) == 0 {
+		return 0.0
+	}
+
+	return perf.NAV[len(perf.NAV)-1] - 1.0
+}
+
+// Returns the annualized total return.
+func (perf *Performance) AnnualizedReturn() float32 {
+	months := len(perf.NAV) - 1
+	if months <= 0 {
+		return 0.0
+	}
+
+	nav := float64(perf.NAV[len(perf.NAV)-1])
+
+	return float32(math.Pow(nav, 12.0/float64(months)) - 1.0)
+}
+
+// Returns the maximum drawdown.
+func (perf *Performance) MaxDrawdown() float32 {
+	var peak, drawdown float32
+
+	for _, nav := range perf.NAV {
+		if nav > peak {
+			peak = nav
+		}
+		if peak > 0.0 && (peak-nav)/peak > drawdown {
+			drawdown = (peak - nav) / peak
+		}
+	}
+
+	return drawdown
+}
+
+/*============================================================================*
+ * Write()                                                                    *
+ *============================================================================*/
+
+// Writes a backtest report into a file.
+func (r *Report) Write(file *os.File) error {
+
+	// Invalid file.
+	if file == nil {
+		return fmt.Errorf("invalid report file")
+	}
+
+	fmt.Fprintf(file, "\nRebalances\n")
+	for _, rebalance := range r.Rebalances {
+		fmt.Fprintf(file, "  %s  turnover %6.2f %% ",
+			rebalance.Date.Format("2006-01-02"),
+			100*rebalance.Turnover,
+		)
+
+		// Print holdings in a stable order.
+		assetIDs := make([]int, 0)
+		for assetID, weight := range rebalance.Allocation {
+			if weight > 0.0 {
+				assetIDs = append(assetIDs, assetID)
+			}
+		}
+		sort.Ints(assetIDs)
+		for _, assetID := range assetIDs {
+			ticker, _ := database.AssetTicker(assetID)
+			fmt.Fprintf(file, " %s:%.2f", ticker, 100*rebalance.Allocation[assetID])
+		}
+		fmt.Fprintf(file, "\n")
+	}
+
+	fmt.Fprintf(file, "\n  %-20s %12s %12s\n", "", "Strategy", "Buy & Hold")
+	fmt.Fprintf(file, "  %-20s %10.2f %% %10.2f %%\n", "Total Return",
+		100*r.Strategy.TotalReturn(), 100*r.Benchmark.TotalReturn())
+	fmt.Fprintf(file, "  %-20s %10.2f %% %10.2f %%\n", "Annualized Return",
+		100*r.Strategy.AnnualizedReturn(), 100*r.Benchmark.AnnualizedReturn())
+	fmt.Fprintf(file, "  %-20s %10.2f %% %10.2f %%\n", "Dividend Income",
+		100*r.Strategy.Income, 100*r.Benchmark.Income)
+	fmt.Fprintf(file, "  %-20s %10.2f %% %10.2f %%\n", "Max. Drawdown",
+		100*r.Strategy.MaxDrawdown(), 100*r.Benchmark.MaxDrawdown())
+	fmt.Fprintf(file, "  %-20s %10.2f %% %10.2f %%\n\n", "Turnover",
+		100*r.Strategy.Turnover, 100*r.Benchmark.Turnover)
+
+	return nil
+}
@@ -0,0 +1,243 @@
+/*
+ * MIT License
+ *
+ * Copyright(c) 2020 Pedro Henrique Penna <pedrohenriquepenna@gmail.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package benchmark
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"portfolio/internal/asset"
+	"portfolio/internal/config"
+	"portfolio/internal/utils"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Name of the Synthetic Benchmark
+const Synthetic = "synthetic"
+
+// Benchmark Series
+type Benchmark struct {
+	name  string      // Name
+	dates []time.Time // Month-End Dates
+	index []float32   // Index Values
+}
+
+// Returns the last day of the month of a date.
+func monthEnd(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month()+1, 0, 0, 0, 0, 0, time.UTC)
+}
+
+// Loads a benchmark from a CSV file of index values. The file must start with
+// a header naming the date and value columns, and values must be sorted by
+// date. Daily values are sampled at the end of each month.
+func Read(filename string) (*Benchmark, error) {
+	var columns map[string]int
+
+	file, err := os.Open(config.BenchmarksPath + filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	bench := &Benchmark{}
+	bench.name = strings.ToUpper(strings.TrimSuffix(filename, ".csv"))
+
+	var last time.Time
+
+	scanner := bufio.NewScanner(file)
+	for lineno := 1; scanner.Scan(); lineno++ {
+		line := scanner.Text()
+
+		// Skip comments.
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+
+		// Skip blank lines.
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		cells := strings.Split(line, ",")
+
+		// Header.
+		if columns == nil {
+			columns = make(map[string]int)
+			for i, name := range cells {
+				columns[strings.ToLower(strings.TrimSpace(name))] = i
+			}
+			for _, name := range []string{"date", "value"} {
+				if _, ok := columns[name]; !ok {
+					return nil, fmt.Errorf("%s:%d: missing column %s", filename, lineno, name)
+				}
+			}
+			continue
+		}
+
+		if len(cells) != len(columns) {
+			return nil, fmt.Errorf("%s:%d: expected %d fields, got %d", filename, lineno, len(columns), len(cells))
+		}
+
+		v := strings.TrimSpace(cells[columns["date"]])
+		date, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid date %s", filename, lineno, v)
+		}
+
+		v = strings.TrimSpace(cells[columns["value"]])
+		value, err := strconv.ParseFloat(v, 32)
+		if err != nil || value <= 0.0 {
+			return nil, fmt.Errorf("%s:%d: invalid value %s", filename, lineno, v)
+		}
+
+		// Check dates.
+		if !last.IsZero() && !date.After(last) {
+			return nil, fmt.Errorf("%s:%d: %s does not follow %s", filename, lineno,
+				date.Format("2006-01-02"), last.Format("2006-01-02"))
+		}
+		last = date
+
+		// Keep the last value of each month.
+		date = monthEnd(date)
+		if n := len(bench.dates); n > 0 && bench.dates[n-1].Equal(date) {
+			bench.index[n-1] = float32(value)
+			continue
+		}
+		if n := len(bench.dates); n > 0 && !monthEnd(bench.dates[n-1].AddDate(0, 0, 1)).Equal(date) {
+			return nil, fmt.Errorf("%s:%d: missing months before %s", filename, lineno, date.Format("2006-01"))
+		}
+
+		bench.dates = append(bench.dates, date)
+		bench.index = append(bench.index, float32(value))
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(bench.dates) < 2 {
+		return nil, fmt.Errorf("%s: too few months", filename)
+	}
+
+	return bench, nil
+}
+
+// Synthesizes a benchmark from a set of assets, weighting the monthly total
+// return of each asset by its market capitalization at the end of the previous
+// month. Months in which no asset has a return carry the index over.
+func Synthesize(assets []*asset.Asset) (*Benchmark, error) {
+	var start, end time.Time
+
+	for _, a := range assets {
+		if start.IsZero() || a.StartDate().Before(start) {
+			start = a.StartDate()
+		}
+		if end.IsZero() || a.EndDate().After(end) {
+			end = a.EndDate()
+		}
+	}
+
+	if start.IsZero() || !end.After(start) {
+		return nil, fmt.Errorf("too few assets to synthesize a benchmark")
+	}
+
+	bench := &Benchmark{}
+	bench.name = "Synthetic"
+	bench.dates = []time.Time{monthEnd(start)}
+	bench.index = []float32{1.0}
+
+	prev := monthEnd(start)
+	for date := monthEnd(prev.AddDate(0, 0, 1)); !date.After(monthEnd(end)); date = monthEnd(date.AddDate(0, 0, 1)) {
+		var total, r float32
+
+		for _, a := range assets {
+			marketCap, ok1 := a.MarketCapAt(prev)
+			first, ok2 := a.TotalReturnIndexAt(prev)
+			curr, ok3 := a.TotalReturnIndexAt(date)
+			if !ok1 || !ok2 || !ok3 || first <= 0.0 {
+				continue
+			}
+
+			total += marketCap
+			r += marketCap * (curr/first - 1.0)
+		}
+
+		value := bench.index[len(bench.index)-1]
+		if total > 0.0 {
+			value *= 1.0 + r/total
+		}
+
+		bench.dates = append(bench.dates, date)
+		bench.index = append(bench.index, value)
+		prev = date
+	}
+
+	return bench, nil
+}
+
+// Loads a benchmark by name: either the synthetic benchmark of a set of assets
+// or a CSV file of index values.
+func Load(name string, assets []*asset.Asset) (*Benchmark, error) {
+	if name == Synthetic {
+		return Synthesize(assets)
+	}
+
+	return Read(name)
+}
+
+// Returns the name of the target benchmark.
+func (bench *Benchmark) Name() string { return bench.name }
+
+// Returns the first month of the target benchmark.
+func (bench *Benchmark) StartDate() time.Time { return bench.dates[0] }
+
+// Returns the last month of the target benchmark.
+func (bench *Benchmark) EndDate() time.Time { return bench.dates[len(bench.dates)-1] }
+
+// Returns the annualized return of the target benchmark over its history.
+func (bench *Benchmark) AnnualizedReturn() float32 {
+	n := len(bench.index)
+	years := utils.YearFrac(bench.dates[0], bench.dates[n-1])
+
+	if years <= 0.0 {
+		return 0.0
+	}
+
+	return float32(math.Pow(float64(bench.index[n-1]/bench.index[0]), 1.0/float64(years))) - 1.0
+}
+
+// Returns the monthly returns of the target benchmark, indexed by month end.
+func (bench *Benchmark) returns() map[time.Time]float32 {
+	returns := make(map[time.Time]float32)
+
+	for t := 1; t < len(bench.index); t++ {
+		returns[bench.dates[t]] = bench.index[t]/bench.index[t-1] - 1.0
+	}
+
+	return returns
+}
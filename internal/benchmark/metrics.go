@@ -0,0 +1,158 @@
+/*
+ * MIT License
+ *
+ * Copyright(c) 2020 Pedro Henrique Penna <pedrohenriquepenna@gmail.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package benchmark
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"time"
+)
+
+// Performance Relative to a Benchmark
+type Metrics struct {
+	Months           int     // Number of Common Months
+	ExcessReturn     float32 // Annualized Return in Excess of the Benchmark
+	TrackingError    float32 // Annualized Standard Deviation of Active Returns
+	InformationRatio float32 // Annualized Active Return per Tracking Error
+	Beta             float32 // Sensitivity to Benchmark Returns
+	UpCapture        float32 // Mean Return in Up Months, Relative to the Benchmark
+	DownCapture      float32 // Mean Return in Down Months, Relative to the Benchmark
+}
+
+// Computes metrics of a total return index relative to the target benchmark,
+// over the months in which both have returns.
+func (bench *Benchmark) Compare(dates []time.Time, index []float32) (*Metrics, error) {
+	benchReturns := bench.returns()
+
+	x := make([]float64, 0, len(dates))
+	y := make([]float64, 0, len(dates))
+
+	for t := 1; t < len(dates); t++ {
+		date := monthEnd(dates[t])
+
+		// Non-consecutive months.
+		if !monthEnd(monthEnd(dates[t-1]).AddDate(0, 0, 1)).Equal(date) {
+			continue
+		}
+		if index[t-1] <= 0.0 {
+			continue
+		}
+
+		r, ok := benchReturns[date]
+		if !ok {
+			continue
+		}
+
+		x = append(x, float64(index[t]/index[t-1]-1.0))
+		y = append(y, float64(r))
+	}
+
+	n := len(x)
+	if n < 2 {
+		return nil, fmt.Errorf("too few months in common with benchmark %s", bench.name)
+	}
+
+	metrics := &Metrics{Months: n}
+
+	// Excess return.
+	growthX, growthY := 1.0, 1.0
+	for t := 0; t < n; t++ {
+		growthX *= 1.0 + x[t]
+		growthY *= 1.0 + y[t]
+	}
+	metrics.ExcessReturn = float32(math.Pow(growthX, 12.0/float64(n)) - math.Pow(growthY, 12.0/float64(n)))
+
+	// Tracking error and information ratio.
+	var meanX, meanY, meanActive float64
+	for t := 0; t < n; t++ {
+		meanX += x[t] / float64(n)
+		meanY += y[t] / float64(n)
+		meanActive += (x[t] - y[t]) / float64(n)
+	}
+	var varActive, varY, covXY float64
+	for t := 0; t < n; t++ {
+		active := x[t] - y[t] - meanActive
+		varActive += active * active / float64(n-1)
+		varY += (y[t] - meanY) * (y[t] - meanY) / float64(n-1)
+		covXY += (x[t] - meanX) * (y[t] - meanY) / float64(n-1)
+	}
+	metrics.TrackingError = float32(math.Sqrt(12.0 * varActive))
+	if varActive > 0.0 {
+		metrics.InformationRatio = float32(12.0 * meanActive / math.Sqrt(12.0*varActive))
+	}
+
+	// Beta.
+	if varY > 0.0 {
+		metrics.Beta = float32(covXY / varY)
+	}
+
+	// Up and down capture.
+	var upX, upY, downX, downY float64
+	for t := 0; t < n; t++ {
+		if y[t] > 0.0 {
+			upX += x[t]
+			upY += y[t]
+		} else if y[t] < 0.0 {
+			downX += x[t]
+			downY += y[t]
+		}
+	}
+	if upY != 0.0 {
+		metrics.UpCapture = float32(upX / upY)
+	}
+	if downY != 0.0 {
+		metrics.DownCapture = float32(downX / downY)
+	}
+
+	return metrics, nil
+}
+
+// Writes the header of a table of metrics relative to the target benchmark
+// into a file.
+func (bench *Benchmark) WriteHeader(file *os.File) {
+	fmt.Fprintf(file, "\nPerformance Relative to %s (%s to %s, %.2f %% a.a.)\n",
+		bench.name,
+		bench.StartDate().Format("2006-01"),
+		bench.EndDate().Format("2006-01"),
+		100*bench.AnnualizedReturn(),
+	)
+	fmt.Fprintf(file, "  %-20s %6s %10s %10s %8s %6s %10s %10s\n",
+		"", "Months", "Excess", "Tracking", "IR", "Beta", "Up Cap.", "Down Cap.")
+}
+
+// Writes metrics relative to a benchmark into a file, as a row of a table.
+func (metrics *Metrics) Write(file *os.File, name string) {
+	fmt.Fprintf(file, "  %-20s %6d %8.2f %% %8.2f %% %8.2f %6.2f %8.2f %% %8.2f %%\n",
+		name,
+		metrics.Months,
+		100*metrics.ExcessReturn,
+		100*metrics.TrackingError,
+		metrics.InformationRatio,
+		metrics.Beta,
+		100*metrics.UpCapture,
+		100*metrics.DownCapture,
+	)
+}
@@ -25,9 +25,16 @@
 package config
 
 const (
-	assetsPath     = "assets/"
-	scriptsPath    = "scripts/"
-	DataPath       = assetsPath + "data/"
-	WalletsPath    = assetsPath + "wallets/"
-	WatchlistsPath = assetsPath + "watchlists/"
+	assetsPath      = "assets/"
+	ActionsPath     = assetsPath + "actions/"
+	BenchmarksPath  = assetsPath + "benchmarks/"
+	scriptsPath     = "scripts/"
+	ConstraintsPath = assetsPath + "constraints/"
+	DataPath        = assetsPath + "data/"
+	DailyPath       = DataPath + "daily/"
+	LedgersPath     = assetsPath + "ledgers/"
+	RegistriesPath  = assetsPath + "registries/"
+	TaxonomiesPath  = assetsPath + "taxonomies/"
+	WalletsPath     = assetsPath + "wallets/"
+	WatchlistsPath  = assetsPath + "watchlists/"
 )
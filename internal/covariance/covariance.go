@@ -0,0 +1,284 @@
+/*
+ * MIT License
+ *
+ * Copyright(c) 2020 Pedro Henrique Penna <pedrohenriquepenna@gmail.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package covariance
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"portfolio/internal/asset"
+	"time"
+)
+
+// Minimum number of common periods to estimate return statistics.
+const MinPeriods = 6
+
+// Return Statistics of a Set of Assets
+type Matrix struct {
+	tickers   []string    // Tickers of Assets
+	mean      []float64   // Annualized Expected Returns
+	cov       [][]float64 // Annualized Covariance Matrix
+	shrinkage float64     // Shrinkage Intensity
+}
+
+// Identifies the month of a date.
+func monthKey(date time.Time) int {
+	return date.Year()*12 + int(date.Month()) - 1
+}
+
+// Indexes the monthly total returns of an asset by month.
+func monthlyReturns(a *asset.Asset) map[int]float64 {
+	series := make(map[int]float64)
+
+	dates, returns := a.TotalReturns()
+	for t := range dates {
+		series[monthKey(dates[t])] = float64(returns[t])
+	}
+
+	return series
+}
+
+// Returns the months common to two return series.
+func commonMonths(x, y map[int]float64) []int {
+	months := make([]int, 0)
+
+	for key := range x {
+		if _, ok := y[key]; ok {
+			months = append(months, key)
+		}
+	}
+
+	return months
+}
+
+// Computes the correlation of two return series over their common months,
+// along with the sum of squared deviations of the products of standardized
+// returns from it, which measures the sampling error of the correlation.
+func correlation(x, y map[int]float64) (float64, float64, int) {
+	var meanX, meanY, c, varX, varY, e float64
+
+	months := commonMonths(x, y)
+	if len(months) < 2 {
+		return 0.0, 0.0, len(months)
+	}
+
+	for _, key := range months {
+		meanX += x[key]
+		meanY += y[key]
+	}
+	meanX /= float64(len(months))
+	meanY /= float64(len(months))
+
+	for _, key := range months {
+		c += (x[key] - meanX) * (y[key] - meanY)
+		varX += (x[key] - meanX) * (x[key] - meanX)
+		varY += (y[key] - meanY) * (y[key] - meanY)
+	}
+	if varX <= 0.0 || varY <= 0.0 {
+		return 0.0, 0.0, len(months)
+	}
+
+	// Standard deviations, as in the correlation.
+	sx := math.Sqrt(varX / float64(len(months)-1))
+	sy := math.Sqrt(varY / float64(len(months)-1))
+	c /= float64(len(months)-1) * sx * sy
+
+	for _, key := range months {
+		d := (x[key]-meanX)*(y[key]-meanY)/(sx*sy) - c
+		e += d * d
+	}
+
+	return c, e, len(months)
+}
+
+// Computes the variance of a return series.
+func variance(x map[int]float64) float64 {
+	var mean, v float64
+
+	for _, r := range x {
+		mean += r
+	}
+	mean /= float64(len(x))
+
+	for _, r := range x {
+		v += (r - mean) * (r - mean)
+	}
+
+	return v / float64(len(x)-1)
+}
+
+// Estimates annualized expected returns and covariances of a set of assets.
+// Each expected return and variance is estimated over the whole history of its
+// asset, and each correlation over the months common to both assets
+// (pairwise-complete). Since histories are short, the sample covariance matrix
+// is shrunk towards a multiple of the identity, with the intensity of Ledoit
+// and Wolf (2004).
+func Estimate(assets []*asset.Asset) (*Matrix, error) {
+
+	if len(assets) == 0 {
+		return nil, fmt.Errorf("empty list of assets")
+	}
+
+	tickers := make([]string, len(assets))
+	series := make([]map[int]float64, len(assets))
+	for i, a := range assets {
+		tickers[i] = a.Ticker()
+		series[i] = monthlyReturns(a)
+	}
+
+	return estimate(tickers, series)
+}
+
+// Estimates annualized expected returns and covariances from monthly return
+// series, indexed by month.
+func estimate(tickers []string, series []map[int]float64) (*Matrix, error) {
+	var trace, dist, err2 float64
+
+	n := len(series)
+
+	m := &Matrix{}
+	m.tickers = make([]string, n)
+	m.mean = make([]float64, n)
+	m.cov = make([][]float64, n)
+	for i := range m.cov {
+		m.cov[i] = make([]float64, n)
+	}
+
+	// Sample statistics.
+	for i := 0; i < n; i++ {
+		m.tickers[i] = tickers[i]
+
+		if len(series[i]) < MinPeriods {
+			return nil, fmt.Errorf("not enough history for %s (%d months)",
+				tickers[i], len(series[i]))
+		}
+
+		for _, r := range series[i] {
+			m.mean[i] += r
+		}
+		m.mean[i] = 12.0 * m.mean[i] / float64(len(series[i]))
+
+		m.cov[i][i] = variance(series[i])
+		trace += m.cov[i][i]
+	}
+
+	for i := 0; i < n; i++ {
+		err2 += m.cov[i][i] * m.cov[i][i] * 2.0 / float64(len(series[i]))
+
+		for j := 0; j < i; j++ {
+			c, e, periods := correlation(series[i], series[j])
+			if periods < MinPeriods {
+				return nil, fmt.Errorf("not enough common history for %s and %s (%d months)",
+					tickers[i], tickers[j], periods)
+			}
+
+			scale := m.cov[i][i] * m.cov[j][j]
+			m.cov[i][j] = c * math.Sqrt(scale)
+			m.cov[j][i] = m.cov[i][j]
+
+			err2 += 2 * scale * e / (float64(periods) * float64(periods))
+		}
+	}
+
+	// Shrinkage intensity.
+	mu := trace / float64(n)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			d := m.cov[i][j]
+			if i == j {
+				d -= mu
+			}
+			dist += d * d
+		}
+	}
+	if dist > 0.0 {
+		m.shrinkage = math.Min(err2, dist) / dist
+	}
+
+	// Shrink and annualize.
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			c := (1.0 - m.shrinkage) * m.cov[i][j]
+			if i == j {
+				c += m.shrinkage * mu
+			}
+			m.cov[i][j] = 12.0 * c
+		}
+	}
+
+	return m, nil
+}
+
+// Returns the number of assets of the target matrix.
+func (m *Matrix) Size() int { return len(m.mean) }
+
+// Returns the shrinkage intensity of the target matrix.
+func (m *Matrix) Shrinkage() float64 { return m.shrinkage }
+
+// Returns the annualized expected return of an asset.
+func (m *Matrix) Mean(i int) float64 { return m.mean[i] }
+
+// Returns the annualized covariance of two assets.
+func (m *Matrix) Cov(i, j int) float64 { return m.cov[i][j] }
+
+// Returns the annualized volatility of an asset.
+func (m *Matrix) Volatility(i int) float64 { return math.Sqrt(m.cov[i][i]) }
+
+// Returns the correlation of two assets.
+func (m *Matrix) Correlation(i, j int) float64 {
+	if m.cov[i][i] <= 0.0 || m.cov[j][j] <= 0.0 {
+		return 0.0
+	}
+
+	return m.cov[i][j] / math.Sqrt(m.cov[i][i]*m.cov[j][j])
+}
+
+// Writes the correlation matrix of the target matrix into a file.
+func (m *Matrix) Write(file *os.File) error {
+
+	// Invalid file.
+	if file == nil {
+		return fmt.Errorf("invalid correlation file")
+	}
+
+	fmt.Fprintf(file, "\nCorrelation Matrix (shrinkage %.2f)\n", m.shrinkage)
+
+	fmt.Fprintf(file, "  %-8s", "")
+	for j := range m.tickers {
+		fmt.Fprintf(file, " %7s", m.tickers[j])
+	}
+	fmt.Fprintf(file, "\n")
+
+	for i := range m.tickers {
+		fmt.Fprintf(file, "  %-8s", m.tickers[i])
+		for j := range m.tickers {
+			fmt.Fprintf(file, " %7.2f", m.Correlation(i, j))
+		}
+		fmt.Fprintf(file, "\n")
+	}
+	fmt.Fprintf(file, "\n")
+
+	return nil
+}
@@ -0,0 +1,144 @@
+/*
+ * MIT License
+ *
+ * Copyright(c) 2020 Pedro Henrique Penna <pedrohenriquepenna@gmail.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package covariance
+
+import (
+	"math"
+	"testing"
+)
+
+// Builds a monthly return series starting at a month.
+func returns(first int, values ...float64) map[int]float64 {
+	series := make(map[int]float64)
+	for t, r := range values {
+		series[first+t] = r
+	}
+
+	return series
+}
+
+func TestEstimate(t *testing.T) {
+	// Sample variance of both alternating series below.
+	v := 8.0 / 7.0
+
+	tests := []struct {
+		name      string            // Test Case
+		series    []map[int]float64 // Monthly Returns
+		shrinkage float64           // Expected Shrinkage Intensity
+		cov       [2][2]float64     // Expected Annualized Covariance Matrix
+	}{
+		{
+			"uncorrelated, equal variances",
+			[]map[int]float64{
+				returns(0, 1, -1, 1, -1, 1, -1, 1, -1),
+				returns(0, 1, 1, -1, -1, 1, 1, -1, -1),
+			},
+			0.0,
+			[2][2]float64{{12 * v, 0.0}, {0.0, 12 * v}},
+		},
+		{
+			"perfectly correlated",
+			[]map[int]float64{
+				returns(0, 1, -1, 1, -1, 1, -1, 1, -1),
+				returns(0, 1, -1, 1, -1, 1, -1, 1, -1),
+			},
+			129.0 / 512.0,
+			[2][2]float64{{12 * v, 12 * v * 383.0 / 512.0}, {12 * v * 383.0 / 512.0, 12 * v}},
+		},
+		{
+			"uncorrelated, close variances",
+			[]map[int]float64{
+				returns(0, 1, -1, 1, -1, 1, -1, 1, -1),
+				returns(0, 1.1, 1.1, -1.1, -1.1, 1.1, 1.1, -1.1, -1.1),
+			},
+			1.0,
+			[2][2]float64{{12 * 1.105 * v, 0.0}, {0.0, 12 * 1.105 * v}},
+		},
+	}
+
+	for _, test := range tests {
+		m, err := estimate([]string{"x", "y"}, test.series)
+		if err != nil {
+			t.Errorf("%s: %s", test.name, err)
+			continue
+		}
+
+		if math.Abs(m.Shrinkage()-test.shrinkage) > 1e-9 {
+			t.Errorf("%s: got shrinkage %.6f, want %.6f", test.name, m.Shrinkage(), test.shrinkage)
+		}
+		for i := 0; i < 2; i++ {
+			for j := 0; j < 2; j++ {
+				if math.Abs(m.Cov(i, j)-test.cov[i][j]) > 1e-9 {
+					t.Errorf("%s: got covariance %.6f at (%d, %d), want %.6f",
+						test.name, m.Cov(i, j), i, j, test.cov[i][j])
+				}
+			}
+		}
+	}
+}
+
+func TestEstimateHistory(t *testing.T) {
+	tests := []struct {
+		name   string            // Test Case
+		series []map[int]float64 // Monthly Returns
+	}{
+		{
+			"short history",
+			[]map[int]float64{
+				returns(0, 1, -1, 1, -1, 1),
+				returns(0, 1, 1, -1, -1, 1, 1, -1, -1),
+			},
+		},
+		{
+			"short common history",
+			[]map[int]float64{
+				returns(0, 1, -1, 1, -1, 1, -1, 1, -1),
+				returns(3, 1, 1, -1, -1, 1, 1, -1, -1),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		if _, err := estimate([]string{"x", "y"}, test.series); err == nil {
+			t.Errorf("%s: got no error", test.name)
+		}
+	}
+}
+
+func TestPortfolioVolatility(t *testing.T) {
+	m, err := estimate([]string{"x", "y"}, []map[int]float64{
+		returns(0, 1, -1, 1, -1, 1, -1, 1, -1),
+		returns(0, 1, 1, -1, -1, 1, 1, -1, -1),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Two uncorrelated assets with equal weights halve the variance.
+	want := math.Sqrt(12 * 8.0 / 7.0 / 2.0)
+	if got := m.PortfolioVolatility([]float64{0.5, 0.5}); math.Abs(got-want) > 1e-9 {
+		t.Errorf("got volatility %.6f, want %.6f", got, want)
+	}
+}
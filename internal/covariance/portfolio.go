@@ -0,0 +1,108 @@
+/*
+ * MIT License
+ *
+ * Copyright(c) 2020 Pedro Henrique Penna <pedrohenriquepenna@gmail.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package covariance
+
+import (
+	"math"
+)
+
+// Computes the expected return of a portfolio.
+func (m *Matrix) PortfolioReturn(w []float64) float64 {
+	var r float64
+
+	for i := range w {
+		r += m.mean[i] * w[i]
+	}
+
+	return r
+}
+
+// Computes the variance of a portfolio.
+func (m *Matrix) PortfolioVariance(w []float64) float64 {
+	var v float64
+
+	for i := range w {
+		for j := range w {
+			v += w[i] * m.cov[i][j] * w[j]
+		}
+	}
+
+	return v
+}
+
+// Computes the volatility of a portfolio.
+func (m *Matrix) PortfolioVolatility(w []float64) float64 {
+	return math.Sqrt(m.PortfolioVariance(w))
+}
+
+// Computes the diversification ratio of a portfolio, that is, the weighted
+// average volatility of its assets over its volatility. It is one for a
+// single asset, and grows as assets are less correlated.
+func (m *Matrix) DiversificationRatio(w []float64) float64 {
+	var avg float64
+
+	volatility := m.PortfolioVolatility(w)
+	if volatility <= 0.0 {
+		return 0.0
+	}
+
+	for i := range w {
+		avg += w[i] * m.Volatility(i)
+	}
+
+	return avg / volatility
+}
+
+// Computes the effective number of bets of a portfolio, as the exponential of
+// the entropy of the contributions of its assets to its variance. It is one
+// for a single asset, and the number of assets if they all contribute alike.
+func (m *Matrix) EffectiveBets(w []float64) float64 {
+	var total, entropy float64
+
+	contributions := make([]float64, len(w))
+	for i := range w {
+		for j := range w {
+			contributions[i] += w[i] * m.cov[i][j] * w[j]
+		}
+
+		// Hedging assets do not count as bets.
+		if contributions[i] > 0.0 {
+			total += contributions[i]
+		}
+	}
+
+	if total <= 0.0 {
+		return 0.0
+	}
+
+	for _, c := range contributions {
+		if c > 0.0 {
+			p := c / total
+			entropy -= p * math.Log(p)
+		}
+	}
+
+	return math.Exp(entropy)
+}
@@ -25,105 +25,250 @@
 package database
 
 import (
+	"bufio"
+	"encoding/csv"
 	"fmt"
+	"os"
 	"portfolio/internal/asset"
 	"portfolio/internal/config"
+	"strings"
+	"time"
 )
 
 // Database Entry
 type dbEntry struct {
-	ticker string // Ticker
-	class  int    // Class
+	ticker        string    // Ticker
+	class         int       // Class
+	cnpj          string    // CNPJ of the Fund (empty if unknown)
+	administrator string    // Administrator of the Fund
+	manager       string    // Manager of the Fund
+	listing       time.Time // Listing Date (zero if unknown)
+	data          string    // Data File Name
+	tags          []string  // Tags
 }
 
-// Classes
+// Registry Columns
 const (
-	Retail = iota
-	Mortgage
-	Office
-	Industrial
-	FoF
+	colTicker = iota
+	colClass
+	colCNPJ
+	colAdministrator
+	colManager
+	colListing
+	colData
+	colTags
+	numColumns
 )
 
-var classesDB = map[int]string{
-	Retail:     "Retail",
-	Mortgage:   "Mortgage",
-	Office:     "Office",
-	Industrial: "Industrial",
-	FoF:        "FoF",
+// Known Assets (indexed by asset ID)
+var assetDB []dbEntry
+
+// Asset IDs (indexed by ticker)
+var tickersDB map[string]int
+
+// Assets database.
+var database []*asset.Asset
+
+// Asserts if a CNPJ, formatted as XX.XXX.XXX/XXXX-XX, has valid check digits.
+func validCNPJ(cnpj string) bool {
+	digits := make([]int, 0, 14)
+
+	for i, c := range cnpj {
+		switch {
+		case c >= '0' && c <= '9':
+			digits = append(digits, int(c-'0'))
+		case (i == 2 || i == 6) && c == '.', i == 10 && c == '/', i == 15 && c == '-':
+		default:
+			return false
+		}
+	}
+
+	if len(digits) != 14 || len(cnpj) != 18 {
+		return false
+	}
+
+	// Check digits are weighted sums modulo 11.
+	for n := 12; n < 14; n++ {
+		var sum int
+		for i := 0; i < n; i++ {
+			sum += digits[i] * (2 + (n-1-i)%8)
+		}
+		dv := 11 - sum%11
+		if dv >= 10 {
+			dv = 0
+		}
+		if digits[n] != dv {
+			return false
+		}
+	}
+
+	return true
 }
 
-// IDs
-const (
-	ALZR11 = iota
-	BPFF11
-	BRCR11
-	HGBS11
-	HGFF11
-	HGLG11
-	HGRE11
-	JSRE11
-	KNCR11
-	KNIP11
-	VISC11
-	XPLG11
-	XPML11
-)
+// Parses a registry entry.
+func parseEntry(line []string) (dbEntry, error) {
+	var err error
+	var entry dbEntry
 
-// Tickers
-const (
-	TickerALZR11 = "alzr11"
-	TickerBPFF11 = "bpff11"
-	TickerBRCR11 = "brcr11"
-	TickerHGBS11 = "hgbs11"
-	TickerHGFF11 = "hgff11"
-	TickerHGLG11 = "hglg11"
-	TickerHGRE11 = "hgre11"
-	TickerJSRE11 = "jsre11"
-	TickerKNCR11 = "kncr11"
-	TickerKNIP11 = "knip11"
-	TickerVISC11 = "visc11"
-	TickerXPLG11 = "xplg11"
-	TickerXPML11 = "xpml11"
-)
+	// Tags are optional.
+	if len(line) == numColumns-1 {
+		line = append(line, "")
+	}
+
+	if len(line) != numColumns {
+		return entry, fmt.Errorf("expected %d fields, got %d", numColumns, len(line))
+	}
+
+	entry.ticker = strings.ToLower(strings.TrimSpace(line[colTicker]))
+	if entry.ticker == "" {
+		return entry, fmt.Errorf("missing ticker")
+	}
+
+	if entry.class, err = GetClassID(strings.TrimSpace(line[colClass])); err != nil {
+		return entry, err
+	}
+
+	entry.cnpj = strings.TrimSpace(line[colCNPJ])
+	if entry.cnpj != "" && !validCNPJ(entry.cnpj) {
+		return entry, fmt.Errorf("invalid CNPJ %s", entry.cnpj)
+	}
+
+	entry.administrator = strings.TrimSpace(line[colAdministrator])
+	entry.manager = strings.TrimSpace(line[colManager])
+
+	if listing := strings.TrimSpace(line[colListing]); listing != "" {
+		if entry.listing, err = time.Parse("2006-01-02", listing); err != nil {
+			return entry, fmt.Errorf("invalid listing date %s", listing)
+		}
+	}
+
+	// Data file defaults to the ticker.
+	entry.data = strings.TrimSpace(line[colData])
+	if entry.data == "" {
+		entry.data = entry.ticker + ".csv"
+	}
 
-// Known Assets
-var assetDB = []dbEntry{
-	{TickerALZR11, Industrial},
-	{TickerBPFF11, FoF},
-	{TickerBRCR11, Office},
-	{TickerHGBS11, Retail},
-	{TickerHGFF11, FoF},
-	{TickerHGLG11, Industrial},
-	{TickerHGRE11, Office},
-	{TickerJSRE11, Office},
-	{TickerKNCR11, Mortgage},
-	{TickerKNIP11, Mortgage},
-	{TickerVISC11, Retail},
-	{TickerXPLG11, Industrial},
-	{TickerXPML11, Retail},
+	// Tags are separated by semicolons.
+	entry.tags = make([]string, 0)
+	for _, tag := range strings.Split(line[colTags], ";") {
+		if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" {
+			entry.tags = append(entry.tags, tag)
+		}
+	}
+
+	return entry, nil
 }
 
-// Assets database.
-var database []*asset.Asset
+// Reads the asset registry from a file. The first line is a header, and each
+// following line lists the ticker, class, CNPJ, administrator, manager,
+// listing date, data file and tags of an asset, quoted as in CSV whenever they
+// contain commas. Blank lines and lines starting with # are ignored. Asset IDs
+// are assigned in the order of the file.
+func readRegistry(filename string) error {
+
+	file, err := os.Open(config.RegistriesPath + filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	assetDB = make([]dbEntry, 0)
+	tickersDB = make(map[string]int)
+
+	scanner := bufio.NewScanner(file)
+
+	// Skip header.
+	if !scanner.Scan() {
+		return fmt.Errorf("%s: missing header", filename)
+	}
+
+	// Read entries.
+	for lineno := 2; scanner.Scan(); lineno++ {
+		line := strings.TrimSpace(scanner.Text())
+
+		// Skip blank lines and comments.
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields, err := csv.NewReader(strings.NewReader(line)).Read()
+		if err != nil {
+			return fmt.Errorf("%s:%d: malformed entry", filename, lineno)
+		}
+
+		entry, err := parseEntry(fields)
+		if err != nil {
+			return fmt.Errorf("%s:%d: %s", filename, lineno, err.Error())
+		}
+
+		if _, ok := tickersDB[entry.ticker]; ok {
+			return fmt.Errorf("%s:%d: duplicate ticker %s", filename, lineno, entry.ticker)
+		}
+
+		tickersDB[entry.ticker] = len(assetDB)
+		assetDB = append(assetDB, entry)
+	}
 
-// Loads storage.
-func Load() {
+	return scanner.Err()
+}
+
+// Loads the class taxonomy and the asset registry, without reading the data
+// of the assets.
+func LoadRegistry(registryFilename string, taxonomyFilename string) error {
+
+	if err := readTaxonomy(taxonomyFilename); err != nil {
+		return err
+	}
+
+	return readRegistry(registryFilename)
+}
+
+// Loads storage from a class taxonomy file and an asset registry file.
+func Load(registryFilename string, taxonomyFilename string) error {
 
 	// Nothing to do.
 	if database != nil {
-		return
+		return nil
+	}
+
+	if err := LoadRegistry(registryFilename, taxonomyFilename); err != nil {
+		return err
 	}
 
-	database = make([]*asset.Asset, 0)
+	database = make([]*asset.Asset, 0, len(assetDB))
 
 	// Load database.
 	fmt.Println("Loading database...")
 	for i := range assetDB {
-		filename := config.DataPath + "/" + assetDB[i].ticker + ".csv"
-		a := asset.Read(i, assetDB[i].ticker, filename, assetDB[i].class)
+		filename := config.DataPath + assetDB[i].data
+		a, err := asset.Read(i, assetDB[i].ticker, filename, assetDB[i].class)
+		if err != nil {
+			database = nil
+			return err
+		}
+
+		// Corporate actions are optional.
+		actions := config.ActionsPath + assetDB[i].ticker + ".actions"
+		if _, err := os.Stat(actions); err == nil {
+			if err := a.ReadActions(actions); err != nil {
+				database = nil
+				return err
+			}
+		}
+
+		// Daily prices are optional.
+		daily := config.DailyPath + assetDB[i].ticker + ".csv"
+		if _, err := os.Stat(daily); err == nil {
+			if err := a.ReadDaily(daily); err != nil {
+				database = nil
+				return err
+			}
+		}
+
 		database = append(database, a)
 	}
+
+	return nil
 }
 
 // Returns the list of known assets.
@@ -131,48 +276,165 @@ func Assets() []*asset.Asset {
 	return database
 }
 
+// Returns the registry entry of an asset.
+func entryOf(assetID int) (*dbEntry, error) {
+
+	if assetID < 0 || assetID >= len(assetDB) {
+		return nil, fmt.Errorf("unknown asset")
+	}
+
+	return &assetDB[assetID], nil
+}
+
 // Gets the ticker of an asset
 func AssetTicker(assetID int) (string, error) {
 
-	return assetDB[assetID].ticker, nil
+	entry, err := entryOf(assetID)
+	if err != nil {
+		return "", err
+	}
+
+	return entry.ticker, nil
+}
+
+// Returns the number of registered assets.
+func NumAssets() int {
+	return len(assetDB)
+}
+
+// Gets the name of the data file of an asset, relative to the data directory.
+func AssetDataFile(assetID int) (string, error) {
+
+	entry, err := entryOf(assetID)
+	if err != nil {
+		return "", err
+	}
+
+	return entry.data, nil
+}
+
+// Gets the CNPJ of the fund of an asset.
+func AssetCNPJ(assetID int) (string, error) {
+
+	entry, err := entryOf(assetID)
+	if err != nil {
+		return "", err
+	}
+
+	return entry.cnpj, nil
+}
+
+// Gets the administrator of the fund of an asset.
+func AssetAdministrator(assetID int) (string, error) {
+
+	entry, err := entryOf(assetID)
+	if err != nil {
+		return "", err
+	}
+
+	return entry.administrator, nil
+}
+
+// Gets the manager of the fund of an asset.
+func AssetManager(assetID int) (string, error) {
+
+	entry, err := entryOf(assetID)
+	if err != nil {
+		return "", err
+	}
+
+	return entry.manager, nil
+}
+
+// Gets the listing date of an asset (zero if unknown).
+func AssetListingDate(assetID int) (time.Time, error) {
+
+	entry, err := entryOf(assetID)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return entry.listing, nil
+}
+
+// Gets the tags of an asset.
+func AssetTags(assetID int) ([]string, error) {
+
+	entry, err := entryOf(assetID)
+	if err != nil {
+		return nil, err
+	}
+
+	return entry.tags, nil
+}
+
+// Asserts if an asset has a tag.
+func HasTag(assetID int, tag string) bool {
+
+	entry, err := entryOf(assetID)
+	if err != nil {
+		return false
+	}
+
+	for _, t := range entry.tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+
+	return false
 }
 
-// Get asset ID.
+// Returns the known tags, in order of first appearance.
+func Tags() []string {
+	tags := make([]string, 0)
+	seen := make(map[string]bool)
+
+	for i := range assetDB {
+		for _, tag := range assetDB[i].tags {
+			if !seen[tag] {
+				seen[tag] = true
+				tags = append(tags, tag)
+			}
+		}
+	}
+
+	return tags
+}
+
+// Get asset ID. Tickers are case insensitive.
 func GetAssetID(ticker string) (int, error) {
 
 	// Look for asset.
-	for i := range database {
-		// Found.
-		if database[i].Ticker() == ticker {
-			return database[i].ID(), nil
-		}
+	if assetID, ok := tickersDB[strings.ToLower(strings.TrimSpace(ticker))]; ok {
+		return assetID, nil
 	}
 
 	return -1, fmt.Errorf("unkown ticker " + ticker)
-
 }
 
 // Get asset by ID.
 func GetAssetByID(ID int) (*asset.Asset, error) {
+
+	if ID < 0 || ID >= len(database) {
+		return nil, fmt.Errorf("unknown asset")
+	}
+
 	return database[ID], nil
 }
 
 // Get asset by ticker.
 func GetAssetByTicker(ticker string) (*asset.Asset, error) {
 
-	// Look for asset.
-	for i := range database {
-		// Found.
-		if database[i].Ticker() == ticker {
-			return database[i], nil
-		}
+	assetID, err := GetAssetID(ticker)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil, fmt.Errorf("unkown ticker " + ticker)
-}
-
-func GetClassName(classID int) (string, error) {
-	className, _ := classesDB[classID]
+	// Only the registry is loaded.
+	if assetID >= len(database) {
+		return nil, fmt.Errorf("data of %s not loaded", ticker)
+	}
 
-	return className, nil
+	return database[assetID], nil
 }
@@ -0,0 +1,75 @@
+/*
+ * MIT License
+ *
+ * Copyright(c) 2020 Pedro Henrique Penna <pedrohenriquepenna@gmail.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package database
+
+import (
+	"os"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+
+	// Paths are relative to the root of the repository.
+	if err := os.Chdir("../.."); err != nil {
+		panic(err.Error())
+	}
+
+	os.Exit(m.Run())
+}
+
+func TestGetAssetByTicker(t *testing.T) {
+	tickers := []string{"alzr11", "ALZR11", " Alzr11\r"}
+
+	// Data of assets is not loaded yet.
+	if err := LoadRegistry("default.registry", "default.taxonomy"); err != nil {
+		t.Fatal(err)
+	}
+	for _, ticker := range tickers {
+		if _, err := GetAssetID(ticker); err != nil {
+			t.Errorf("%q: %s", ticker, err)
+		}
+		if _, err := GetAssetByTicker(ticker); err == nil {
+			t.Errorf("%q: got asset without data", ticker)
+		}
+	}
+
+	if err := Load("default.registry", "default.taxonomy"); err != nil {
+		t.Fatal(err)
+	}
+	for _, ticker := range tickers {
+		a, err := GetAssetByTicker(ticker)
+		if err != nil {
+			t.Errorf("%q: %s", ticker, err)
+			continue
+		}
+		if a.Ticker() != "alzr11" {
+			t.Errorf("%q: got %s", ticker, a.Ticker())
+		}
+	}
+
+	if _, err := GetAssetByTicker("xxxx11"); err == nil {
+		t.Errorf("got unknown ticker")
+	}
+}
@@ -0,0 +1,262 @@
+/*
+ * MIT License
+ *
+ * Copyright(c) 2020 Pedro Henrique Penna <pedrohenriquepenna@gmail.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package database
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"portfolio/internal/config"
+	"strings"
+)
+
+// Separator of Class Names in a Class Path
+const classSeparator = "/"
+
+// Class Entry
+type classEntry struct {
+	name   string // Name
+	path   string // Full Path (e.g. Mortgage/High-Grade-CRI)
+	parent int    // Parent Class ID (-1 for top-level classes)
+	depth  int    // Depth in the Taxonomy (zero for top-level classes)
+}
+
+// Known Classes (indexed by class ID)
+var classesDB []classEntry
+
+// Number of Levels in the Taxonomy
+var numLevels int
+
+// Adds a class given its full path, adding its ancestors if needed.
+func addClass(path string) int {
+
+	// Already known.
+	for classID := range classesDB {
+		if strings.EqualFold(classesDB[classID].path, path) {
+			return classID
+		}
+	}
+
+	parent := -1
+	name := path
+	if i := strings.LastIndex(path, classSeparator); i >= 0 {
+		parent = addClass(path[:i])
+		name = path[i+1:]
+	}
+
+	entry := classEntry{name: name, path: path, parent: parent}
+	if parent >= 0 {
+		entry.depth = classesDB[parent].depth + 1
+	}
+	if entry.depth+1 > numLevels {
+		numLevels = entry.depth + 1
+	}
+
+	classesDB = append(classesDB, entry)
+
+	return len(classesDB) - 1
+}
+
+// Reads the class taxonomy from a file. Each line gives the full path of a
+// class, with sub-classes separated by slashes from their parents (e.g.
+// Retail/Shopping-Malls). Class IDs are assigned in the order of the file.
+func readTaxonomy(filename string) error {
+
+	file, err := os.Open(config.TaxonomiesPath + filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	classesDB = make([]classEntry, 0)
+	numLevels = 0
+
+	scanner := bufio.NewScanner(file)
+
+	// Read classes.
+	for lineno := 1; scanner.Scan(); lineno++ {
+		line := scanner.Text()
+
+		// Skip comments.
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+
+		path := strings.TrimSpace(line)
+
+		// Skip blank lines.
+		if path == "" {
+			continue
+		}
+
+		if strings.ContainsAny(path, " \t,") {
+			return fmt.Errorf("%s:%d: invalid class %s", filename, lineno, path)
+		}
+		for _, name := range strings.Split(path, classSeparator) {
+			if name == "" {
+				return fmt.Errorf("%s:%d: invalid class %s", filename, lineno, path)
+			}
+		}
+
+		addClass(path)
+	}
+
+	return scanner.Err()
+}
+
+// Returns the number of known classes.
+func NumClasses() int {
+	return len(classesDB)
+}
+
+// Returns the number of levels in the taxonomy.
+func NumLevels() int {
+	return numLevels
+}
+
+// Gets the name of a class.
+func GetClassName(classID int) (string, error) {
+
+	if classID < 0 || classID >= len(classesDB) {
+		return "", fmt.Errorf("unknown class")
+	}
+
+	return classesDB[classID].name, nil
+}
+
+// Gets the full path of a class.
+func GetClassPath(classID int) (string, error) {
+
+	if classID < 0 || classID >= len(classesDB) {
+		return "", fmt.Errorf("unknown class")
+	}
+
+	return classesDB[classID].path, nil
+}
+
+// Gets the ID of a class given its full path or, if not ambiguous, its name.
+func GetClassID(className string) (int, error) {
+	classID := -1
+
+	// Look for class.
+	for i := range classesDB {
+		// Found.
+		if strings.EqualFold(classesDB[i].path, className) {
+			return i, nil
+		}
+
+		if strings.EqualFold(classesDB[i].name, className) {
+			if classID >= 0 {
+				return -1, fmt.Errorf("ambiguous class " + className)
+			}
+			classID = i
+		}
+	}
+
+	if classID < 0 {
+		return -1, fmt.Errorf("unknown class " + className)
+	}
+
+	return classID, nil
+}
+
+// Returns the parent of a class (-1 for top-level classes).
+func ClassParent(classID int) int {
+	return classesDB[classID].parent
+}
+
+// Returns the depth of a class in the taxonomy (zero for top-level classes).
+func ClassDepth(classID int) int {
+	return classesDB[classID].depth
+}
+
+// Returns the ancestor of a class at a given depth of the taxonomy, or the
+// class itself if it lies above that depth.
+func ClassAncestor(classID int, depth int) int {
+	for classesDB[classID].depth > depth {
+		classID = classesDB[classID].parent
+	}
+
+	return classID
+}
+
+// Asserts if a class is a sub-class of another one (or the class itself).
+func IsSubclass(classID int, ancestorID int) bool {
+	for ; classID >= 0; classID = classesDB[classID].parent {
+		if classID == ancestorID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Computes the allocation in each class, given the classes of a set of
+// assets and the allocation in these assets. The allocation in a class
+// includes that of its sub-classes.
+func ClassAllocation(classIDs []int, allocation []float32) []float32 {
+	classes := make([]float32, len(classesDB))
+
+	for i := range allocation {
+		for classID := classIDs[i]; classID >= 0; classID = classesDB[classID].parent {
+			classes[classID] += allocation[i]
+		}
+	}
+
+	return classes
+}
+
+// Computes the diversification of an allocation across classes, given the
+// classes of a set of assets and the allocation in these assets. At each
+// level of the taxonomy, diversification is one minus the allocation in the
+// largest class, and the result is the average over all levels. Assets of a
+// class that has no sub-classes count as a class of their own at deeper
+// levels.
+func Diversification(classIDs []int, allocation []float32) float32 {
+	var diversification float32
+
+	if numLevels == 0 {
+		return 0.0
+	}
+
+	for depth := 0; depth < numLevels; depth++ {
+		var concentration float32
+		classes := make(map[int]float32)
+
+		for i := range allocation {
+			classes[ClassAncestor(classIDs[i], depth)] += allocation[i]
+		}
+
+		for _, x := range classes {
+			if x > concentration {
+				concentration = x
+			}
+		}
+
+		diversification += 1 - concentration
+	}
+
+	return diversification / float32(numLevels)
+}
@@ -0,0 +1,373 @@
+/*
+ * MIT License
+ *
+ * Copyright(c) 2020 Pedro Henrique Penna <pedrohenriquepenna@gmail.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package importer
+
+import (
+	"archive/zip"
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"portfolio/internal/asset"
+	"portfolio/internal/config"
+	"portfolio/internal/database"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Layout of COTAHIST Quote Records
+const (
+	cotahistRecordLength = 245        // Record Length
+	cotahistQuoteType    = "01"       // Record Type of Quotes
+	cotahistSpotMarket   = "010"      // Market Type of Spot Quotes
+	cotahistDateLayout   = "20060102" // Date Layout
+)
+
+// Field of a Fixed-Width Record (one-based, inclusive positions)
+type fixedField struct {
+	start int // Start Position
+	end   int // End Position
+}
+
+// Fields of COTAHIST Quote Records
+var (
+	cotahistType     = fixedField{1, 2}     // Record Type
+	cotahistDate     = fixedField{3, 10}    // Trading Date
+	cotahistTicker   = fixedField{13, 24}   // Ticker
+	cotahistMarket   = fixedField{25, 27}   // Market Type
+	cotahistOpen     = fixedField{57, 69}   // Open Price
+	cotahistHigh     = fixedField{70, 82}   // High Price
+	cotahistLow      = fixedField{83, 95}   // Low Price
+	cotahistClose    = fixedField{109, 121} // Close Price
+	cotahistTrades   = fixedField{148, 152} // Number of Trades
+	cotahistQuantity = fixedField{153, 170} // Number of Shares Traded
+	cotahistVolume   = fixedField{171, 188} // Financial Volume
+	cotahistFactor   = fixedField{211, 217} // Quote Factor
+)
+
+// Daily Quote
+type Quote struct {
+	Date     time.Time // Trading Date
+	Ticker   string    // Ticker
+	Open     float64   // Open Price
+	High     float64   // High Price
+	Low      float64   // Low Price
+	Close    float64   // Close Price
+	Trades   int       // Number of Trades
+	Quantity int64     // Number of Shares Traded
+	Volume   float64   // Financial Volume
+}
+
+// Daily Data File Columns
+var dailyHeader = []string{"date", "open", "high", "low", "close", "volume", "quantity", "trades"}
+
+// Extracts a field from a fixed-width record.
+func (f fixedField) extract(line string) string {
+	return strings.TrimSpace(line[f.start-1 : f.end])
+}
+
+// Extracts a price field, given in cents, from a fixed-width record.
+func (f fixedField) price(line string, factor float64) (float64, error) {
+	cents, err := strconv.ParseInt(f.extract(line), 10, 64)
+	if err != nil {
+		return 0.0, err
+	}
+
+	return float64(cents) / 100.0 / factor, nil
+}
+
+// Parses a quote record of a COTAHIST file.
+func parseQuote(line string) (*Quote, error) {
+	var err error
+
+	q := &Quote{}
+
+	if q.Date, err = time.Parse(cotahistDateLayout, cotahistDate.extract(line)); err != nil {
+		return nil, fmt.Errorf("invalid date")
+	}
+
+	q.Ticker = strings.ToLower(cotahistTicker.extract(line))
+
+	factor, err := strconv.Atoi(cotahistFactor.extract(line))
+	if err != nil || factor <= 0 {
+		return nil, fmt.Errorf("invalid quote factor")
+	}
+
+	if q.Open, err = cotahistOpen.price(line, float64(factor)); err != nil {
+		return nil, fmt.Errorf("invalid open price")
+	}
+	if q.High, err = cotahistHigh.price(line, float64(factor)); err != nil {
+		return nil, fmt.Errorf("invalid high price")
+	}
+	if q.Low, err = cotahistLow.price(line, float64(factor)); err != nil {
+		return nil, fmt.Errorf("invalid low price")
+	}
+	if q.Close, err = cotahistClose.price(line, float64(factor)); err != nil {
+		return nil, fmt.Errorf("invalid close price")
+	}
+	if q.Trades, err = strconv.Atoi(cotahistTrades.extract(line)); err != nil {
+		return nil, fmt.Errorf("invalid number of trades")
+	}
+	if q.Quantity, err = strconv.ParseInt(cotahistQuantity.extract(line), 10, 64); err != nil {
+		return nil, fmt.Errorf("invalid quantity")
+	}
+	if q.Volume, err = cotahistVolume.price(line, 1.0); err != nil {
+		return nil, fmt.Errorf("invalid volume")
+	}
+
+	return q, nil
+}
+
+// Reads the spot quotes of some tickers from a COTAHIST stream.
+func readQuotes(reader io.Reader, name string, tickers map[string]bool) ([]*Quote, error) {
+	quotes := make([]*Quote, 0)
+
+	scanner := bufio.NewScanner(reader)
+	for lineno := 1; scanner.Scan(); lineno++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		// Skip header, trailer and other records.
+		if len(line) < cotahistRecordLength || cotahistType.extract(line) != cotahistQuoteType {
+			continue
+		}
+		if cotahistMarket.extract(line) != cotahistSpotMarket {
+			continue
+		}
+		if !tickers[strings.ToLower(cotahistTicker.extract(line))] {
+			continue
+		}
+
+		q, err := parseQuote(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %s", name, lineno, err.Error())
+		}
+
+		quotes = append(quotes, q)
+	}
+
+	return quotes, scanner.Err()
+}
+
+// Reads the spot quotes of some tickers from a COTAHIST file, either plain or
+// zipped, as published by B3.
+func ReadCOTAHIST(filename string, tickers map[string]bool) ([]*Quote, error) {
+
+	// Plain file.
+	if !strings.EqualFold(filepath.Ext(filename), ".zip") {
+		file, err := os.Open(filename)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		return readQuotes(file, filename, tickers)
+	}
+
+	// Zipped file.
+	archive, err := zip.OpenReader(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer archive.Close()
+
+	quotes := make([]*Quote, 0)
+	for _, f := range archive.File {
+		reader, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+
+		q, err := readQuotes(reader, filename+":"+f.Name, tickers)
+		reader.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		quotes = append(quotes, q...)
+	}
+
+	return quotes, nil
+}
+
+// Merges the month-end closes of daily quotes of an asset into its data
+// file. Records of existing months get their price updated, and records are
+// added for the months following the last one. The current month is skipped
+// until it is over, since its last close is not the month-end close yet. Other
+// columns are left untouched.
+func mergeMonthly(assetID int, quotes []*Quote, dryRun bool) ([]*Change, error) {
+	changes := make([]*Change, 0)
+
+	ticker, _ := database.AssetTicker(assetID)
+	data, _ := database.AssetDataFile(assetID)
+	filename := config.DataPath + data
+
+	t, err := readTable(filename, asset.Columns())
+	if err != nil {
+		return nil, err
+	}
+	if t.column("date") < 0 || t.column("price") < 0 {
+		return nil, fmt.Errorf("%s: missing date or price column", filename)
+	}
+
+	// Month-end closes.
+	closes := make(map[time.Time]*Quote)
+	for _, q := range quotes {
+		month := monthEnd(q.Date)
+		if last, ok := closes[month]; !ok || q.Date.After(last.Date) {
+			closes[month] = q
+		}
+	}
+
+	months := make([]time.Time, 0, len(closes))
+	for month := range closes {
+		months = append(months, month)
+	}
+	sort.Slice(months, func(i, j int) bool { return months[i].Before(months[j]) })
+
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	for _, month := range months {
+
+		// Unfinished month.
+		if !month.Before(today) {
+			fmt.Fprintf(os.Stderr, "%s: skipping %s, month not finished\n",
+				ticker, month.Format("2006-01"))
+			continue
+		}
+
+		row := t.monthRow(month)
+
+		// New month.
+		if row < 0 {
+			n := len(t.rows)
+			if n > 0 && monthsBetween(t.date(n-1), month) != 1 {
+				fmt.Fprintf(os.Stderr, "%s: skipping %s, not contiguous to the data\n",
+					ticker, month.Format("2006-01"))
+				continue
+			}
+			row = t.addRow(month)
+		}
+
+		if c := t.set(ticker, row, "price", formatNumber(closes[month].Close)); c != nil {
+			changes = append(changes, c)
+		}
+	}
+
+	if !dryRun && len(changes) > 0 {
+		return changes, t.persist(filename)
+	}
+
+	return changes, nil
+}
+
+// Merges daily quotes of an asset into its daily data file.
+func mergeDaily(assetID int, quotes []*Quote, dryRun bool) error {
+
+	ticker, _ := database.AssetTicker(assetID)
+	filename := config.DailyPath + ticker + ".csv"
+
+	t, err := readTable(filename, dailyHeader)
+	if os.IsNotExist(err) {
+		t = newTable(dailyHeader)
+	} else if err != nil {
+		return err
+	}
+
+	for _, q := range quotes {
+		row := t.dayRow(q.Date)
+		if row < 0 {
+			row = t.addRow(q.Date)
+		}
+		t.set(ticker, row, "open", formatNumber(q.Open))
+		t.set(ticker, row, "high", formatNumber(q.High))
+		t.set(ticker, row, "low", formatNumber(q.Low))
+		t.set(ticker, row, "close", formatNumber(q.Close))
+		t.set(ticker, row, "volume", formatNumber(q.Volume))
+		t.set(ticker, row, "quantity", strconv.FormatInt(q.Quantity, 10))
+		t.set(ticker, row, "trades", strconv.Itoa(q.Trades))
+	}
+
+	if dryRun {
+		return nil
+	}
+
+	if err := os.MkdirAll(config.DailyPath, 0755); err != nil {
+		return err
+	}
+
+	return t.persist(filename)
+}
+
+// Imports COTAHIST files into the data of registered assets. Month-end closes
+// are merged into the monthly data files and, optionally, daily quotes are
+// merged into daily data files. Nothing is written in a dry run.
+func ImportCOTAHIST(filenames []string, daily bool, dryRun bool) ([]*Change, error) {
+	changes := make([]*Change, 0)
+
+	// Registered tickers.
+	tickers := make(map[string]bool)
+	for assetID := 0; assetID < database.NumAssets(); assetID++ {
+		ticker, _ := database.AssetTicker(assetID)
+		tickers[ticker] = true
+	}
+
+	// Read quotes.
+	quotes := make(map[int][]*Quote)
+	for _, filename := range filenames {
+		q, err := ReadCOTAHIST(filename, tickers)
+		if err != nil {
+			return nil, err
+		}
+		for _, quote := range q {
+			assetID, _ := database.GetAssetID(quote.Ticker)
+			quotes[assetID] = append(quotes[assetID], quote)
+		}
+	}
+
+	// Merge quotes.
+	for assetID := 0; assetID < database.NumAssets(); assetID++ {
+		if len(quotes[assetID]) == 0 {
+			continue
+		}
+
+		c, err := mergeMonthly(assetID, quotes[assetID], dryRun)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, c...)
+
+		if daily {
+			if err := mergeDaily(assetID, quotes[assetID], dryRun); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return changes, nil
+}
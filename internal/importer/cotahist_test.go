@@ -0,0 +1,91 @@
+/*
+ * MIT License
+ *
+ * Copyright(c) 2020 Pedro Henrique Penna <pedrohenriquepenna@gmail.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package importer
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Formats a quote record as laid out in the COTAHIST specification.
+func cotahistRecord(date, ticker, market string, prices [5]int64, trades int, quantity, volume int64, factor int) string {
+	return fmt.Sprintf("%-2s%-8s%-2s%-12s%-3s%-12s%-10s%-3s%-4s", "01", date, "12", ticker, market, "FII CSHG LOG", "CI", "", "R$") +
+		fmt.Sprintf("%013d%013d%013d%013d%013d%013d%013d", prices[0], prices[1], prices[2], prices[3], prices[4], 0, 0) +
+		fmt.Sprintf("%05d%018d%018d%013d%1d%-8s%07d%013d%-12s%03d", trades, quantity, volume, 0, 0, "99991231", factor, 0, "BRHGLGCTF004", 0)
+}
+
+func TestParseQuote(t *testing.T) {
+	tests := []struct {
+		line  string // Record
+		quote Quote  // Expected Quote
+	}{
+		{
+			cotahistRecord("20200131", "HGLG11", "010", [5]int64{18050, 18290, 17900, 18110, 18200}, 2500, 41000, 742510000, 1),
+			Quote{time.Date(2020, time.January, 31, 0, 0, 0, 0, time.UTC), "hglg11",
+				180.50, 182.90, 179.00, 182.00, 2500, 41000, 7425100.00},
+		},
+		{
+			cotahistRecord("20191230", "KNCR11", "010", [5]int64{1050000, 1060000, 1040000, 1055000, 1058000}, 12, 300, 31740000, 100),
+			Quote{time.Date(2019, time.December, 30, 0, 0, 0, 0, time.UTC), "kncr11",
+				105.00, 106.00, 104.00, 105.80, 12, 300, 317400.00},
+		},
+	}
+
+	for _, test := range tests {
+		if len(test.line) != cotahistRecordLength {
+			t.Fatalf("record has %d characters, want %d", len(test.line), cotahistRecordLength)
+		}
+
+		q, err := parseQuote(test.line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if *q != test.quote {
+			t.Errorf("got %+v, want %+v", *q, test.quote)
+		}
+	}
+}
+
+func TestReadQuotes(t *testing.T) {
+	prices := [5]int64{10000, 10000, 10000, 10000, 10000}
+
+	lines := []string{
+		fmt.Sprintf("%-245s", "00COTAHIST.2020BOVESPA 20200131"),
+		cotahistRecord("20200131", "HGLG11", "010", prices, 1, 1, 10000, 1),
+		cotahistRecord("20200131", "HGLG11", "020", prices, 1, 1, 10000, 1), // Odd lot
+		cotahistRecord("20200131", "KNCR11", "010", prices, 1, 1, 10000, 1), // Not registered
+		fmt.Sprintf("%-245s", "99COTAHIST.2020BOVESPA 20200131"),
+	}
+
+	quotes, err := readQuotes(strings.NewReader(strings.Join(lines, "\r\n")), "test", map[string]bool{"hglg11": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(quotes) != 1 || quotes[0].Ticker != "hglg11" {
+		t.Errorf("got %d quotes, want 1 quote of hglg11", len(quotes))
+	}
+}
@@ -0,0 +1,317 @@
+/*
+ * MIT License
+ *
+ * Copyright(c) 2020 Pedro Henrique Penna <pedrohenriquepenna@gmail.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package importer
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"portfolio/internal/asset"
+	"portfolio/internal/config"
+	"portfolio/internal/database"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Separator of CVM Files
+const cvmSeparator = ";"
+
+// Columns of CVM Monthly Reports that Identify a Report
+var (
+	cvmCNPJColumns    = []string{"CNPJ_Fundo", "CNPJ_Fundo_Classe"} // CNPJ of the Fund
+	cvmDateColumns    = []string{"Data_Referencia"}                 // Reference Date
+	cvmVersionColumns = []string{"Versao"}                          // Version of the Report
+)
+
+// Data Column Filled from a Column of CVM Monthly Reports
+type cvmColumn struct {
+	names   []string // Column Names in CVM Files
+	integer bool     // Integer Column?
+}
+
+// Data Columns Filled from CVM Monthly Reports (indexed by data column name)
+var cvmColumns = map[string]cvmColumn{
+	"bvps":    {[]string{"Valor_Patrimonial_Cotas"}, false},
+	"equity":  {[]string{"Patrimonio_Liquido"}, false},
+	"shares":  {[]string{"Cotas_Emitidas"}, true},
+	"holders": {[]string{"Total_Numero_Cotistas"}, true},
+}
+
+// Monthly Report of a Fund
+type cvmReport struct {
+	cnpj    string            // CNPJ of the Fund (digits only)
+	date    time.Time         // Reference Date
+	version int               // Version of the Report
+	values  map[string]string // Values (indexed by data column name)
+}
+
+// Key of a Monthly Report
+type cvmKey struct {
+	cnpj  string // CNPJ of the Fund (digits only)
+	month int    // Reference Month
+}
+
+// Returns the digits of a CNPJ.
+func cnpjDigits(cnpj string) string {
+	var b strings.Builder
+
+	for _, c := range cnpj {
+		if c >= '0' && c <= '9' {
+			b.WriteRune(c)
+		}
+	}
+
+	return b.String()
+}
+
+// Returns the index of the first column of a header with one of some names,
+// or -1 if there is no such column.
+func findColumn(header []string, names []string) int {
+	for i := range header {
+		for _, name := range names {
+			if strings.EqualFold(strings.Trim(header[i], " \"\ufeff"), name) {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+// Parses a number of a CVM file, which may use a decimal comma.
+func parseCVMNumber(value string, integer bool) (string, error) {
+	if strings.Contains(value, ",") && !strings.Contains(value, ".") {
+		value = strings.Replace(value, ",", ".", 1)
+	}
+
+	x, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid value %s", value)
+	}
+
+	if integer {
+		return strconv.FormatInt(int64(math.Round(x)), 10), nil
+	}
+
+	return formatNumber(x), nil
+}
+
+// Reads the monthly reports of some funds from a CVM file. Files without the
+// columns that identify reports are ignored.
+func readCVM(filename string, cnpjs map[string]bool, reports map[cvmKey]*cvmReport) error {
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	// Read header.
+	if !scanner.Scan() {
+		return scanner.Err()
+	}
+	header := strings.Split(scanner.Text(), cvmSeparator)
+
+	cnpjColumn := findColumn(header, cvmCNPJColumns)
+	dateColumn := findColumn(header, cvmDateColumns)
+	versionColumn := findColumn(header, cvmVersionColumns)
+	if cnpjColumn < 0 || dateColumn < 0 {
+		return nil
+	}
+
+	columns := make(map[string]int)
+	for name, c := range cvmColumns {
+		if i := findColumn(header, c.names); i >= 0 {
+			columns[name] = i
+		}
+	}
+	if len(columns) == 0 {
+		return nil
+	}
+
+	// Read reports.
+	for lineno := 2; scanner.Scan(); lineno++ {
+		cells := strings.Split(scanner.Text(), cvmSeparator)
+		for i := range cells {
+			cells[i] = strings.Trim(cells[i], " \"")
+		}
+		if len(cells) != len(header) {
+			return fmt.Errorf("%s:%d: expected %d fields, got %d", filename, lineno, len(header), len(cells))
+		}
+
+		cnpj := cnpjDigits(cells[cnpjColumn])
+		if !cnpjs[cnpj] {
+			continue
+		}
+
+		date, err := time.Parse("2006-01-02", cells[dateColumn])
+		if err != nil {
+			return fmt.Errorf("%s:%d: invalid date %s", filename, lineno, cells[dateColumn])
+		}
+
+		version := 0
+		if versionColumn >= 0 {
+			version, _ = strconv.Atoi(cells[versionColumn])
+		}
+
+		// Keep the latest version.
+		key := cvmKey{cnpj, date.Year()*12 + int(date.Month()) - 1}
+		r, ok := reports[key]
+		if !ok || version > r.version {
+			r = &cvmReport{cnpj: cnpj, date: date, version: version}
+			r.values = make(map[string]string)
+			reports[key] = r
+		} else if version < r.version {
+			continue
+		}
+
+		for name, i := range columns {
+			if cells[i] == "" {
+				continue
+			}
+			value, err := parseCVMNumber(cells[i], cvmColumns[name].integer)
+			if err != nil {
+				return fmt.Errorf("%s:%d: %s: %s", filename, lineno, header[i], err.Error())
+			}
+			r.values[name] = value
+		}
+	}
+
+	return scanner.Err()
+}
+
+// Fills the data file of an asset with monthly reports of its fund. Only
+// months that already have a record are filled.
+func mergeReports(assetID int, reports []*cvmReport, dryRun bool) ([]*Change, error) {
+	changes := make([]*Change, 0)
+
+	ticker, _ := database.AssetTicker(assetID)
+	data, _ := database.AssetDataFile(assetID)
+	filename := config.DataPath + data
+
+	t, err := readTable(filename, asset.Columns())
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(reports, func(i, j int) bool { return reports[i].date.Before(reports[j].date) })
+
+	for _, r := range reports {
+		row := t.monthRow(r.date)
+		if row < 0 {
+			fmt.Fprintf(os.Stderr, "%s: skipping %s, no record for this month\n",
+				ticker, r.date.Format("2006-01"))
+			continue
+		}
+
+		names := make([]string, 0, len(r.values))
+		for name := range r.values {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			if t.column(name) < 0 {
+				continue
+			}
+			if c := t.set(ticker, row, name, r.values[name]); c != nil {
+				changes = append(changes, c)
+			}
+		}
+	}
+
+	if !dryRun && len(changes) > 0 {
+		return changes, t.persist(filename)
+	}
+
+	return changes, nil
+}
+
+// Imports the CVM monthly reports (Informe Mensal Estruturado) found in some
+// directories into the data of registered assets, matching funds by CNPJ.
+// Nothing is written in a dry run.
+func ImportCVM(dirnames []string, dryRun bool) ([]*Change, error) {
+	changes := make([]*Change, 0)
+
+	// Registered CNPJs.
+	cnpjs := make(map[string]bool)
+	assets := make(map[string]int)
+	for assetID := 0; assetID < database.NumAssets(); assetID++ {
+		cnpj, _ := database.AssetCNPJ(assetID)
+		if cnpj = cnpjDigits(cnpj); cnpj == "" {
+			ticker, _ := database.AssetTicker(assetID)
+			fmt.Fprintf(os.Stderr, "%s: skipping, no CNPJ in the registry\n", ticker)
+			continue
+		}
+		cnpjs[cnpj] = true
+		assets[cnpj] = assetID
+	}
+
+	// Read reports.
+	reports := make(map[cvmKey]*cvmReport)
+	for _, dirname := range dirnames {
+		files, err := ioutil.ReadDir(dirname)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range files {
+			if f.IsDir() || !strings.EqualFold(filepath.Ext(f.Name()), ".csv") {
+				continue
+			}
+			if err := readCVM(filepath.Join(dirname, f.Name()), cnpjs, reports); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Group reports by asset.
+	byAsset := make(map[int][]*cvmReport)
+	for _, r := range reports {
+		byAsset[assets[r.cnpj]] = append(byAsset[assets[r.cnpj]], r)
+	}
+
+	// Merge reports.
+	for assetID := 0; assetID < database.NumAssets(); assetID++ {
+		if len(byAsset[assetID]) == 0 {
+			continue
+		}
+
+		c, err := mergeReports(assetID, byAsset[assetID], dryRun)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, c...)
+	}
+
+	return changes, nil
+}
@@ -0,0 +1,107 @@
+/*
+ * MIT License
+ *
+ * Copyright(c) 2020 Pedro Henrique Penna <pedrohenriquepenna@gmail.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package importer
+
+import (
+	"path/filepath"
+	"portfolio/internal/asset"
+	"testing"
+	"time"
+)
+
+// CVM Fixture
+const cvmFixture = "testdata/cvm/inf_mensal_fii_complemento_2020.csv"
+
+func TestReadCVM(t *testing.T) {
+	cnpj := "11728688000147"
+
+	tests := []struct {
+		month  int               // Reference Month
+		values map[string]string // Expected Values (indexed by data column name)
+	}{
+		// Latest version of the report.
+		{2020*12 + 0, map[string]string{"bvps": "159.0702", "equity": "2545123456.78", "shares": "16000000", "holders": "150322"}},
+		// Quoted cells with decimal commas.
+		{2020*12 + 1, map[string]string{"bvps": "159.375", "equity": "2550000000.5", "shares": "16000000", "holders": "160000"}},
+	}
+
+	reports := make(map[cvmKey]*cvmReport)
+	if err := readCVM(cvmFixture, map[string]bool{cnpj: true}, reports); err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) != len(tests) {
+		t.Fatalf("got %d reports, want %d", len(reports), len(tests))
+	}
+
+	for _, test := range tests {
+		r, ok := reports[cvmKey{cnpj, test.month}]
+		if !ok {
+			t.Fatalf("missing report of month %d", test.month)
+		}
+		for name, value := range test.values {
+			if r.values[name] != value {
+				t.Errorf("month %d: got %s %s, want %s", test.month, name, r.values[name], value)
+			}
+		}
+	}
+}
+
+// Merged reports must be read back into asset records.
+func TestCVMColumns(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "hglg11.csv")
+
+	reports := make(map[cvmKey]*cvmReport)
+	if err := readCVM(cvmFixture, map[string]bool{"11728688000147": true}, reports); err != nil {
+		t.Fatal(err)
+	}
+
+	data := newTable(asset.Columns())
+	for _, r := range reports {
+		row := data.addRow(monthEnd(r.date))
+		data.set("hglg11", row, "price", "180")
+		for name, value := range r.values {
+			if data.column(name) < 0 {
+				t.Fatalf("unknown data column %s", name)
+			}
+			data.set("hglg11", row, name, value)
+		}
+	}
+	if err := data.persist(filename); err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := asset.Read(0, "hglg11", filename, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.NumRecords() != 2 || !a.EndDate().Equal(time.Date(2020, time.February, 29, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("got %d records up to %s, want 2 up to 2020-02-29", a.NumRecords(), a.EndDate().Format("2006-01-02"))
+	}
+
+	// Price, book value and equity out of five fields.
+	if a.Completeness() != 0.6 {
+		t.Errorf("got completeness %.2f, want 0.60", a.Completeness())
+	}
+}
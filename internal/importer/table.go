@@ -0,0 +1,339 @@
+/*
+ * MIT License
+ *
+ * Copyright(c) 2020 Pedro Henrique Penna <pedrohenriquepenna@gmail.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package importer
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"portfolio/internal/asset"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Data File Edited as Text
+//
+// Cells are kept as they are read, so that columns that are not touched by an
+// importer are written back unchanged. Comments are kept as well, each one
+// attached to the row that follows it.
+type table struct {
+	header   []string            // Column Names
+	rows     [][]string          // Rows (sorted by date)
+	comments map[string][]string // Comment Lines (indexed by the date of the next row)
+	notes    map[string]string   // Inline Comments (indexed by the date of their row)
+}
+
+// Keys of Comments not Followed by a Row
+const (
+	headerKey = "header" // Comments before the Header
+	endKey    = "end"    // Comments at the End of the File
+)
+
+// Change to a Data File
+type Change struct {
+	Ticker string    // Ticker
+	Date   time.Time // Date of the Record
+	Column string    // Column Name
+	Old    string    // Old Value (empty for new records)
+	New    string    // New Value
+}
+
+// Returns the last day of the month of a date.
+func monthEnd(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month()+1, 0, 0, 0, 0, 0, time.UTC)
+}
+
+// Returns the number of months between two dates.
+func monthsBetween(a, b time.Time) int {
+	return (b.Year()-a.Year())*12 + int(b.Month()) - int(a.Month())
+}
+
+// Formats a number without trailing zeros.
+func formatNumber(x float64) string {
+	return strconv.FormatFloat(x, 'f', -1, 64)
+}
+
+// Asserts if two cells hold the same value.
+func sameValue(a, b string) bool {
+	x, errx := strconv.ParseFloat(a, 64)
+	y, erry := strconv.ParseFloat(b, 64)
+	if errx == nil && erry == nil {
+		return x == y
+	}
+
+	return strings.EqualFold(a, b)
+}
+
+// Reads a data file as text. Files without a header get the default one.
+func readTable(filename string, defaultHeader []string) (*table, error) {
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	t := newTable(nil)
+
+	pending := make([]string, 0)
+
+	scanner := bufio.NewScanner(file)
+	for lineno := 1; scanner.Scan(); lineno++ {
+		line := strings.TrimSpace(scanner.Text())
+
+		// Skip blank lines.
+		if line == "" {
+			continue
+		}
+
+		// Comment line.
+		if strings.HasPrefix(line, "#") {
+			pending = append(pending, line)
+			continue
+		}
+
+		// Inline comment.
+		note := ""
+		if i := strings.Index(line, "#"); i >= 0 {
+			note = strings.TrimSpace(line[i:])
+			line = strings.TrimSpace(line[:i])
+		}
+
+		cells := strings.Split(line, ",")
+		for i := range cells {
+			cells[i] = strings.TrimSpace(cells[i])
+		}
+
+		// Header.
+		if t.header == nil {
+			if _, err := time.Parse("2006-01-02", cells[0]); err != nil {
+				t.header = cells
+				t.comments[headerKey] = pending
+				pending = make([]string, 0)
+				continue
+			}
+			t.header = defaultHeader
+		}
+
+		if len(cells) != len(t.header) {
+			return nil, fmt.Errorf("%s:%d: expected %d fields, got %d",
+				filename, lineno, len(t.header), len(cells))
+		}
+		if _, err := time.Parse("2006-01-02", cells[t.column("date")]); err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid date", filename, lineno)
+		}
+
+		key := cells[t.column("date")]
+		if len(pending) > 0 {
+			t.comments[key] = pending
+			pending = make([]string, 0)
+		}
+		if note != "" {
+			t.notes[key] = note
+		}
+
+		t.rows = append(t.rows, cells)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(pending) > 0 {
+		t.comments[endKey] = pending
+	}
+
+	if t.header == nil {
+		t.header = defaultHeader
+	}
+
+	return t, nil
+}
+
+// Creates an empty table.
+func newTable(header []string) *table {
+	t := &table{}
+
+	t.header = header
+	t.rows = make([][]string, 0)
+	t.comments = make(map[string][]string)
+	t.notes = make(map[string]string)
+
+	return t
+}
+
+// Returns the index of a column, or -1 if there is no such column.
+func (t *table) column(name string) int {
+	for i := range t.header {
+		if strings.EqualFold(t.header[i], name) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// Returns the date of a row.
+func (t *table) date(row int) time.Time {
+	date, _ := time.Parse("2006-01-02", t.rows[row][t.column("date")])
+	return date
+}
+
+// Returns the row in the month of a date, or -1 if there is no such row.
+func (t *table) monthRow(date time.Time) int {
+	for row := range t.rows {
+		if monthsBetween(t.date(row), date) == 0 {
+			return row
+		}
+	}
+
+	return -1
+}
+
+// Returns the row of a date, or -1 if there is no such row.
+func (t *table) dayRow(date time.Time) int {
+	for row := range t.rows {
+		if t.date(row).Equal(date) {
+			return row
+		}
+	}
+
+	return -1
+}
+
+// Adds a row for a date with missing values, keeping rows sorted by date.
+func (t *table) addRow(date time.Time) int {
+	cells := make([]string, len(t.header))
+	for i := range cells {
+		cells[i] = asset.MissingValue
+	}
+	cells[t.column("date")] = date.Format("2006-01-02")
+
+	t.rows = append(t.rows, cells)
+	sort.SliceStable(t.rows, func(i, j int) bool {
+		return t.rows[i][t.column("date")] < t.rows[j][t.column("date")]
+	})
+
+	return t.dayRow(date)
+}
+
+// Sets a cell, returning the change made, if any.
+func (t *table) set(ticker string, row int, column string, value string) *Change {
+	col := t.column(column)
+	old := t.rows[row][col]
+
+	if sameValue(old, value) {
+		return nil
+	}
+
+	t.rows[row][col] = value
+
+	if strings.EqualFold(old, asset.MissingValue) {
+		old = ""
+	}
+
+	return &Change{Ticker: ticker, Date: t.date(row), Column: column, Old: old, New: value}
+}
+
+// Writes a table to a file, with a header. The table is first written to a
+// temporary file in the same directory, which then replaces the file, so that
+// a failure never leaves a truncated file behind.
+func (t *table) persist(filename string) error {
+
+	file, err := ioutil.TempFile(filepath.Dir(filename), filepath.Base(filename)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	// Keep the permissions of the file.
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(filename); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := file.Chmod(mode); err != nil {
+		file.Close()
+		return err
+	}
+
+	writer := bufio.NewWriter(file)
+
+	for _, comment := range t.comments[headerKey] {
+		fmt.Fprintf(writer, "%s\n", comment)
+	}
+	fmt.Fprintf(writer, "%s\n", strings.Join(t.header, ","))
+
+	for _, cells := range t.rows {
+		key := cells[t.column("date")]
+		for _, comment := range t.comments[key] {
+			fmt.Fprintf(writer, "%s\n", comment)
+		}
+		if note, ok := t.notes[key]; ok {
+			fmt.Fprintf(writer, "%s %s\n", strings.Join(cells, ","), note)
+		} else {
+			fmt.Fprintf(writer, "%s\n", strings.Join(cells, ","))
+		}
+	}
+
+	for _, comment := range t.comments[endKey] {
+		fmt.Fprintf(writer, "%s\n", comment)
+	}
+
+	if err := writer.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), filename)
+}
+
+// Writes a report of changes into a file.
+func WriteChanges(file *os.File, changes []*Change) error {
+
+	// Invalid file.
+	if file == nil {
+		return fmt.Errorf("invalid report file")
+	}
+
+	fmt.Fprintf(file, "\n%d changes\n", len(changes))
+	for _, c := range changes {
+		old := c.Old
+		if old == "" {
+			old = "-"
+		}
+		fmt.Fprintf(file, "  %-6s %s %-10s %15s -> %s\n",
+			c.Ticker, c.Date.Format("2006-01-02"), c.Column, old, c.New)
+	}
+	fmt.Fprintf(file, "\n")
+
+	return nil
+}
@@ -0,0 +1,113 @@
+/*
+ * MIT License
+ *
+ * Copyright(c) 2020 Pedro Henrique Penna <pedrohenriquepenna@gmail.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package ledger
+
+import (
+	"fmt"
+	"portfolio/internal/database"
+	"time"
+)
+
+// Position
+type Position struct {
+	Quantity int     // Number of Shares
+	Cost     float64 // Total Acquisition Cost
+}
+
+// Cost Basis of Positions (indexed by asset ID)
+type Positions map[int]*Position
+
+// Creates an empty set of positions.
+func NewPositions() Positions {
+	return make(Positions)
+}
+
+// Applies a transaction to the target positions, and returns the gain that it
+// realizes. Purchase fees are part of the acquisition cost and sale fees reduce
+// the proceeds. Returns of capital reduce the acquisition cost, and the excess
+// over it is realized as a gain. Cash transactions and dividends do not change
+// positions.
+func (ps Positions) Apply(t *Transaction) (float64, error) {
+	p := ps[t.AssetID]
+
+	switch t.Kind {
+
+	case Buy, Subscription:
+		if p == nil {
+			p = &Position{}
+			ps[t.AssetID] = p
+		}
+		p.Quantity += t.Quantity
+		p.Cost += t.Value() + t.Fees
+
+	case Sell:
+		if p == nil || p.Quantity < t.Quantity {
+			return 0.0, fmt.Errorf("%s: selling more shares than held", t.String())
+		}
+		cost := p.Cost * float64(t.Quantity) / float64(p.Quantity)
+		p.Quantity -= t.Quantity
+		p.Cost -= cost
+		if p.Quantity == 0 {
+			delete(ps, t.AssetID)
+		}
+
+		return t.Value() - t.Fees - cost, nil
+
+	case Amortization:
+		if p == nil {
+			return 0.0, fmt.Errorf("%s: amortization of a position not held", t.String())
+		}
+		p.Cost -= t.Amount
+		if p.Cost < 0.0 {
+			gain := -p.Cost
+			p.Cost = 0.0
+			return gain, nil
+		}
+	}
+
+	return 0.0, nil
+}
+
+// Adjusts the number of shares of the target positions for the splits and
+// groupings of their assets with ex-dates after a date and up to another one.
+// Acquisition costs are kept, and fractions of shares left by groupings are
+// dropped.
+func (ps Positions) Split(from, to time.Time) {
+	for assetID, p := range ps {
+
+		// Assets without data have no known corporate actions.
+		a, err := database.GetAssetByID(assetID)
+		if err != nil {
+			continue
+		}
+
+		if factor := a.SharesFactor(from, to); factor != 1.0 {
+			p.Quantity = int(float64(p.Quantity)*float64(factor) + 1e-3)
+			if p.Quantity == 0 {
+				delete(ps, assetID)
+			}
+		}
+	}
+}
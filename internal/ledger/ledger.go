@@ -0,0 +1,299 @@
+/*
+ * MIT License
+ *
+ * Copyright(c) 2020 Pedro Henrique Penna <pedrohenriquepenna@gmail.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package ledger
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"portfolio/internal/config"
+	"portfolio/internal/database"
+	"portfolio/internal/wallet"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Ledger
+type Ledger struct {
+	name         string         // Name
+	transactions []*Transaction // Transactions (sorted by date)
+}
+
+// Replay State
+type state struct {
+	positions Positions // Positions
+	cash      float64   // Cash Balance
+	realized  float64   // Realized Gain
+	income    float64   // Dividend Income
+	date      time.Time // Date of the State
+}
+
+// Creates an empty ledger.
+func New(name string) *Ledger {
+	l := &Ledger{}
+
+	l.name = name
+	l.transactions = make([]*Transaction, 0)
+
+	return l
+}
+
+// Returns the name of the target ledger.
+func (l *Ledger) Name() string {
+	return l.name
+}
+
+// Returns the transactions of the target ledger, sorted by date.
+func (l *Ledger) Transactions() []*Transaction {
+	return l.transactions
+}
+
+// Adds a transaction to the target ledger.
+func (l *Ledger) Add(t *Transaction) {
+	l.transactions = append(l.transactions, t)
+
+	// Keep transactions sorted by date.
+	sort.SliceStable(l.transactions, func(i, j int) bool {
+		return l.transactions[i].Date.Before(l.transactions[j].Date)
+	})
+}
+
+/*============================================================================*
+ * Read()                                                                     *
+ *============================================================================*/
+
+// Reads a ledger from a file. The first line is the name of the ledger, and
+// each following line a transaction. Blank lines and comments are ignored.
+func Read(filename string) (*Ledger, error) {
+
+	file, err := os.Open(config.LedgersPath + filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+
+	// Read ledger name.
+	if !scanner.Scan() {
+		return nil, fmt.Errorf("corrupted ledger file")
+	}
+	l := New(strings.TrimSpace(scanner.Text()))
+
+	// Read transactions.
+	for lineno := 2; scanner.Scan(); lineno++ {
+		line := scanner.Text()
+
+		// Skip comments.
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+
+		fields := strings.Fields(line)
+
+		// Skip blank lines.
+		if len(fields) == 0 {
+			continue
+		}
+
+		t, err := parseTransaction(fields)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %s", filename, lineno, err.Error())
+		}
+		l.Add(t)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+/*============================================================================*
+ * Persist()                                                                  *
+ *============================================================================*/
+
+// Persists the target ledger into a file.
+func (l *Ledger) Persist(filename string) error {
+
+	file, err := os.Create(config.LedgersPath + filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	fmt.Fprintf(file, "%s\n", l.name)
+	for _, t := range l.transactions {
+		fmt.Fprintf(file, "%s\n", t.String())
+	}
+
+	return nil
+}
+
+/*============================================================================*
+ * Replay()                                                                   *
+ *============================================================================*/
+
+// Creates an empty replay state.
+func newState() *state {
+	s := &state{}
+
+	s.positions = NewPositions()
+
+	return s
+}
+
+// Moves a replay state forward to a given date, adjusting positions for the
+// splits and groupings in between.
+func (s *state) advance(date time.Time) {
+	s.positions.Split(s.date, date)
+	s.date = date
+}
+
+// Applies a transaction to a replay state.
+func (s *state) apply(t *Transaction) error {
+	s.advance(t.Date)
+
+	gain, err := s.positions.Apply(t)
+	if err != nil {
+		return err
+	}
+	s.realized += gain
+
+	switch t.Kind {
+	case Deposit, Amortization:
+		s.cash += t.Amount
+	case Withdrawal:
+		s.cash -= t.Amount
+	case Buy, Subscription:
+		s.cash -= t.Value() + t.Fees
+	case Sell:
+		s.cash += t.Value() - t.Fees
+	case Dividend:
+		s.cash += t.Amount
+		s.income += t.Amount
+	}
+
+	return nil
+}
+
+// Replays the transactions of the target ledger up to (and including) a given
+// date. Positions are adjusted for the splits and groupings up to that date.
+func (l *Ledger) replay(date time.Time) (*state, error) {
+	s := newState()
+
+	for _, t := range l.transactions {
+		if t.Date.After(date) {
+			break
+		}
+		if err := s.apply(t); err != nil {
+			return nil, err
+		}
+	}
+	s.advance(date)
+
+	return s, nil
+}
+
+// Derives the wallet at a given date by replaying the target ledger. Holdings
+// are valued at the share prices of that date.
+func (l *Ledger) Replay(date time.Time) (*wallet.Wallet, error) {
+
+	s, err := l.replay(date)
+	if err != nil {
+		return nil, err
+	}
+
+	w := wallet.New(l.name)
+	w.SetValuationDate(date)
+	for assetID, p := range s.positions {
+		w.SetHolding(assetID, p.Quantity, float32(p.Cost/float64(p.Quantity)))
+	}
+	w.SetCash(float32(s.cash))
+	w.SetRealizedGain(float32(s.realized))
+	w.SetIncome(float32(s.income))
+
+	return w, nil
+}
+
+/*============================================================================*
+ * WriteHistory()                                                             *
+ *============================================================================*/
+
+// Returns the last day of the month of a date.
+func monthEnd(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month()+1, 0, 0, 0, 0, 0, time.UTC)
+}
+
+// Writes the month-by-month evolution of the target ledger into a file, from
+// the first transaction up to a given date. Positions are valued at the share
+// price of each month, or at cost when no price is available.
+func (l *Ledger) WriteHistory(file *os.File, to time.Time) error {
+
+	// Invalid file.
+	if file == nil {
+		return fmt.Errorf("invalid history file")
+	}
+
+	if len(l.transactions) == 0 {
+		return nil
+	}
+
+	fmt.Fprintf(file, "\nHistory of %s\n", l.name)
+	fmt.Fprintf(file, "  %-10s %12s %12s %12s %12s %12s\n",
+		"Month", "Cash", "Cost", "Value", "Realized", "Income")
+
+	from := l.transactions[0].Date
+	for date := monthEnd(from); !date.After(monthEnd(to)); date = monthEnd(date.AddDate(0, 0, 1)) {
+		var cost, value float64
+
+		s, err := l.replay(date)
+		if err != nil {
+			return err
+		}
+
+		for assetID, p := range s.positions {
+			cost += p.Cost
+
+			a, err := database.GetAssetByID(assetID)
+			if err != nil {
+				return err
+			}
+			if price, ok := a.RawSharePriceAt(date); ok {
+				value += float64(price) * float64(p.Quantity)
+			} else {
+				value += p.Cost
+			}
+		}
+
+		fmt.Fprintf(file, "  %-10s %12.2f %12.2f %12.2f %12.2f %12.2f\n",
+			date.Format("2006-01"), s.cash, cost, value, s.realized, s.income)
+	}
+	fmt.Fprintf(file, "\n")
+
+	return nil
+}
@@ -0,0 +1,100 @@
+/*
+ * MIT License
+ *
+ * Copyright(c) 2020 Pedro Henrique Penna <pedrohenriquepenna@gmail.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package ledger
+
+import (
+	"math"
+	"os"
+	"portfolio/internal/database"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+
+	// Paths are relative to the root of the repository.
+	if err := os.Chdir("../.."); err != nil {
+		panic(err.Error())
+	}
+	if err := database.Load("default.registry", "default.taxonomy"); err != nil {
+		panic(err.Error())
+	}
+
+	os.Exit(m.Run())
+}
+
+// Parses a date.
+func day(s string) time.Time {
+	date, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	return date
+}
+
+func TestReplaySplit(t *testing.T) {
+	assetID, err := database.GetAssetID("hglg11")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Shares of hglg11 were split 1:10 on 2018-04-01.
+	l := &Ledger{name: "test"}
+	l.transactions = []*Transaction{
+		{Date: day("2018-01-02"), Kind: Deposit, Amount: 20000.0},
+		{Date: day("2018-01-15"), Kind: Buy, AssetID: assetID, Quantity: 10, Price: 1300.0},
+		{Date: day("2018-08-10"), Kind: Sell, AssetID: assetID, Quantity: 50, Price: 126.0},
+	}
+
+	tests := []struct {
+		date     time.Time // Replay Date
+		quantity int       // Expected Number of Shares
+		price    float32   // Expected Share Price
+	}{
+		{day("2018-02-15"), 10, 1379.99},
+		{day("2018-06-15"), 100, 124.99},
+		{day("2018-08-31"), 50, 126.02},
+	}
+
+	for _, test := range tests {
+		w, err := l.Replay(test.date)
+		if err != nil {
+			t.Errorf("%s: %s", test.date.Format("2006-01-02"), err)
+			continue
+		}
+
+		h, ok := w.Holding(assetID)
+		if !ok || h.Quantity != test.quantity {
+			t.Errorf("%s: got holding %v, want %d shares", test.date.Format("2006-01-02"), h, test.quantity)
+			continue
+		}
+
+		want := float32(test.quantity) * test.price
+		if got := w.PositionsValue(); math.Abs(float64(got-want)) > 0.01 {
+			t.Errorf("%s: got value %.2f, want %.2f", test.date.Format("2006-01-02"), got, want)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+/*
+ * MIT License
+ *
+ * Copyright(c) 2020 Pedro Henrique Penna <pedrohenriquepenna@gmail.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package ledger
+
+import (
+	"fmt"
+	"portfolio/internal/database"
+	"strings"
+	"time"
+)
+
+// Transaction Kinds
+const (
+	Deposit      = iota // Cash Deposit
+	Withdrawal          // Cash Withdrawal
+	Buy                 // Purchase of Shares
+	Sell                // Sale of Shares
+	Dividend            // Dividend Receipt
+	Amortization        // Return of Capital
+	Subscription        // Subscription of New Shares
+)
+
+var kindsDB = map[int]string{
+	Deposit:      "deposit",
+	Withdrawal:   "withdrawal",
+	Buy:          "buy",
+	Sell:         "sell",
+	Dividend:     "dividend",
+	Amortization: "amortization",
+	Subscription: "subscription",
+}
+
+// Transaction
+type Transaction struct {
+	Date     time.Time // Date
+	Kind     int       // Kind
+	AssetID  int       // Asset ID (not used for cash transactions)
+	Quantity int       // Number of Shares (buy, sell and subscription)
+	Price    float64   // Share Price (buy, sell and subscription)
+	Fees     float64   // Fees (buy, sell and subscription)
+	Amount   float64   // Amount (deposit, withdrawal, dividend and amortization)
+}
+
+// Returns the name of a transaction kind.
+func KindName(kind int) string {
+	return kindsDB[kind]
+}
+
+// Asserts if a transaction trades shares.
+func (t *Transaction) isTrade() bool {
+	return t.Kind == Buy || t.Kind == Sell || t.Kind == Subscription
+}
+
+// Returns the gross value of a transaction.
+func (t *Transaction) Value() float64 {
+	if t.isTrade() {
+		return float64(t.Quantity) * t.Price
+	}
+
+	return t.Amount
+}
+
+/*============================================================================*
+ * parseTransaction()                                                         *
+ *============================================================================*/
+
+// Parses a transaction from the fields of a ledger line:
+//
+//	<date> deposit <amount>
+//	<date> withdrawal <amount>
+//	<date> buy <ticker> <quantity> <price> [fees]
+//	<date> sell <ticker> <quantity> <price> [fees]
+//	<date> subscription <ticker> <quantity> <price> [fees]
+//	<date> dividend <ticker> <amount>
+//	<date> amortization <ticker> <amount>
+func parseTransaction(fields []string) (*Transaction, error) {
+	var err error
+
+	if len(fields) < 3 {
+		return nil, fmt.Errorf("incomplete transaction")
+	}
+
+	t := &Transaction{}
+	t.AssetID = -1
+
+	if t.Date, err = time.Parse("2006-01-02", fields[0]); err != nil {
+		return nil, fmt.Errorf("invalid date " + fields[0])
+	}
+
+	t.Kind = -1
+	for kind, name := range kindsDB {
+		if strings.EqualFold(name, fields[1]) {
+			t.Kind = kind
+		}
+	}
+
+	switch t.Kind {
+
+	case Deposit, Withdrawal:
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("usage: <date> %s <amount>", fields[1])
+		}
+		if _, err = fmt.Sscanf(fields[2], "%f", &t.Amount); err != nil || t.Amount < 0.0 {
+			return nil, fmt.Errorf("invalid amount " + fields[2])
+		}
+
+	case Dividend, Amortization:
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("usage: <date> %s <ticker> <amount>", fields[1])
+		}
+		if t.AssetID, err = database.GetAssetID(fields[2]); err != nil {
+			return nil, err
+		}
+		if _, err = fmt.Sscanf(fields[3], "%f", &t.Amount); err != nil || t.Amount < 0.0 {
+			return nil, fmt.Errorf("invalid amount " + fields[3])
+		}
+
+	case Buy, Sell, Subscription:
+		if len(fields) != 5 && len(fields) != 6 {
+			return nil, fmt.Errorf("usage: <date> %s <ticker> <quantity> <price> [fees]", fields[1])
+		}
+		if t.AssetID, err = database.GetAssetID(fields[2]); err != nil {
+			return nil, err
+		}
+		if _, err = fmt.Sscanf(fields[3], "%d", &t.Quantity); err != nil || t.Quantity <= 0 {
+			return nil, fmt.Errorf("invalid quantity " + fields[3])
+		}
+		if _, err = fmt.Sscanf(fields[4], "%f", &t.Price); err != nil || t.Price < 0.0 {
+			return nil, fmt.Errorf("invalid price " + fields[4])
+		}
+		if len(fields) == 6 {
+			if _, err = fmt.Sscanf(fields[5], "%f", &t.Fees); err != nil || t.Fees < 0.0 {
+				return nil, fmt.Errorf("invalid fees " + fields[5])
+			}
+		}
+
+	default:
+		return nil, fmt.Errorf("unknown transaction " + fields[1])
+	}
+
+	return t, nil
+}
+
+/*============================================================================*
+ * String()                                                                   *
+ *============================================================================*/
+
+// Formats a transaction as a ledger line.
+func (t *Transaction) String() string {
+	date := t.Date.Format("2006-01-02")
+
+	switch t.Kind {
+	case Deposit, Withdrawal:
+		return fmt.Sprintf("%s %s %.2f", date, kindsDB[t.Kind], t.Amount)
+	case Dividend, Amortization:
+		ticker, _ := database.AssetTicker(t.AssetID)
+		return fmt.Sprintf("%s %s %s %.2f", date, kindsDB[t.Kind], ticker, t.Amount)
+	}
+
+	ticker, _ := database.AssetTicker(t.AssetID)
+	s := fmt.Sprintf("%s %s %s %d %.2f", date, kindsDB[t.Kind], ticker, t.Quantity, t.Price)
+	if t.Fees > 0.0 {
+		s += fmt.Sprintf(" %.2f", t.Fees)
+	}
+
+	return s
+}
@@ -0,0 +1,648 @@
+/*
+ * MIT License
+ *
+ * Copyright(c) 2020 Pedro Henrique Penna <pedrohenriquepenna@gmail.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package optimizer
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"portfolio/internal/config"
+	"portfolio/internal/database"
+	"sort"
+	"strings"
+)
+
+// Tolerance used when checking constraints.
+const epsilon = 1e-4
+
+// Maximum number of passes of the repair operator.
+const maxRepairPasses = 8
+
+// Allocation Bounds
+type Bounds struct {
+	Min float32 // Minimum Allocation
+	Max float32 // Maximum Allocation
+}
+
+// Allocation Constraints
+type Constraints struct {
+	assets       map[int]Bounds  // Per-Asset Bounds (indexed by asset ID)
+	classes      map[int]Bounds  // Per-Class Bounds (indexed by class ID)
+	locks        map[int]float32 // Locked Positions (negative means current weight)
+	excluded     map[int]bool    // Excluded Assets
+	minHoldings  int             // Minimum Number of Holdings
+	maxHoldings  int             // Maximum Number of Holdings (zero means unbounded)
+	completeness float32         // Minimum Data Completeness
+}
+
+// Creates an empty set of constraints.
+func NewConstraints() *Constraints {
+	c := &Constraints{}
+
+	c.assets = make(map[int]Bounds)
+	c.classes = make(map[int]Bounds)
+	c.locks = make(map[int]float32)
+	c.excluded = make(map[int]bool)
+
+	return c
+}
+
+// Sets the allocation bounds of an asset. A positive minimum forces the asset
+// to be held.
+func (c *Constraints) SetAssetBounds(assetID int, min, max float32) {
+	c.assets[assetID] = Bounds{Min: min, Max: max}
+}
+
+// Sets the allocation bounds of a class.
+func (c *Constraints) SetClassBounds(classID int, min, max float32) {
+	c.classes[classID] = Bounds{Min: min, Max: max}
+}
+
+// Sets the minimum and maximum number of holdings.
+func (c *Constraints) SetHoldings(min, max int) {
+	c.minHoldings = min
+	c.maxHoldings = max
+}
+
+// Locks the position of an asset at a given weight. A negative weight locks
+// the asset at its current weight (see ResolveLocks()).
+func (c *Constraints) Lock(assetID int, weight float32) {
+	c.locks[assetID] = weight
+}
+
+// Sets the minimum data completeness of held assets. Assets with less complete
+// data are excluded, unless they are locked or must be held.
+func (c *Constraints) SetMinCompleteness(completeness float32) {
+	c.completeness = completeness
+}
+
+// Excludes an asset from the allocation.
+func (c *Constraints) Exclude(assetID int) {
+	c.excluded[assetID] = true
+}
+
+// Resolves positions locked at their current weight, given the current
+// allocation.
+func (c *Constraints) ResolveLocks(current map[int]float32) {
+	for assetID, weight := range c.locks {
+		if weight < 0.0 {
+			c.locks[assetID] = current[assetID]
+		}
+	}
+}
+
+/*============================================================================*
+ * ReadConstraints()                                                          *
+ *============================================================================*/
+
+// Parses a percentage.
+func parsePercent(s string) (float32, error) {
+	var value float32
+
+	if _, err := fmt.Sscanf(s, "%f", &value); err != nil {
+		return 0.0, fmt.Errorf("invalid percentage " + s)
+	}
+
+	if value < 0.0 || value > 100.0 {
+		return 0.0, fmt.Errorf("percentage out of range " + s)
+	}
+
+	return value / 100.0, nil
+}
+
+// Parses a line of a constraints file.
+func (c *Constraints) parse(fields []string) error {
+	var err error
+	var min, max float32
+
+	switch fields[0] {
+
+	// asset <ticker> <min> <max>
+	case "asset":
+		if len(fields) != 4 {
+			return fmt.Errorf("usage: asset <ticker> <min> <max>")
+		}
+		assetID, err := database.GetAssetID(fields[1])
+		if err != nil {
+			return err
+		}
+		if min, err = parsePercent(fields[2]); err != nil {
+			return err
+		}
+		if max, err = parsePercent(fields[3]); err != nil {
+			return err
+		}
+		c.SetAssetBounds(assetID, min, max)
+
+	// class <class> <min> <max>
+	case "class":
+		if len(fields) != 4 {
+			return fmt.Errorf("usage: class <class> <min> <max>")
+		}
+		classID, err := database.GetClassID(fields[1])
+		if err != nil {
+			return err
+		}
+		if min, err = parsePercent(fields[2]); err != nil {
+			return err
+		}
+		if max, err = parsePercent(fields[3]); err != nil {
+			return err
+		}
+		c.SetClassBounds(classID, min, max)
+
+	// holdings <min> <max>
+	case "holdings":
+		var minHoldings, maxHoldings int
+		if len(fields) != 3 {
+			return fmt.Errorf("usage: holdings <min> <max>")
+		}
+		if _, err = fmt.Sscanf(fields[1]+" "+fields[2], "%d %d", &minHoldings, &maxHoldings); err != nil {
+			return fmt.Errorf("invalid number of holdings")
+		}
+		c.SetHoldings(minHoldings, maxHoldings)
+
+	// lock <ticker> [weight]
+	case "lock":
+		weight := float32(-1.0)
+		if len(fields) != 2 && len(fields) != 3 {
+			return fmt.Errorf("usage: lock <ticker> [weight]")
+		}
+		assetID, err := database.GetAssetID(fields[1])
+		if err != nil {
+			return err
+		}
+		if len(fields) == 3 {
+			if weight, err = parsePercent(fields[2]); err != nil {
+				return err
+			}
+		}
+		c.Lock(assetID, weight)
+
+	// exclude <ticker>
+	case "exclude":
+		if len(fields) != 2 {
+			return fmt.Errorf("usage: exclude <ticker>")
+		}
+		assetID, err := database.GetAssetID(fields[1])
+		if err != nil {
+			return err
+		}
+		c.Exclude(assetID)
+
+	// completeness <min>
+	case "completeness":
+		if len(fields) != 2 {
+			return fmt.Errorf("usage: completeness <min>")
+		}
+		if min, err = parsePercent(fields[1]); err != nil {
+			return err
+		}
+		c.SetMinCompleteness(min)
+
+	default:
+		return fmt.Errorf("unknown constraint " + fields[0])
+	}
+
+	return nil
+}
+
+// Reads constraints from a file.
+func ReadConstraints(filename string) (*Constraints, error) {
+
+	file, err := os.Open(config.ConstraintsPath + filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	c := NewConstraints()
+
+	// Read constraints.
+	scanner := bufio.NewScanner(file)
+	for lineno := 1; scanner.Scan(); lineno++ {
+		line := scanner.Text()
+
+		// Skip comments.
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+
+		fields := strings.Fields(line)
+
+		// Skip blank lines.
+		if len(fields) == 0 {
+			continue
+		}
+
+		if err := c.parse(fields); err != nil {
+			return nil, fmt.Errorf("%s:%d: %s", filename, lineno, err.Error())
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+/*============================================================================*
+ * Feasible Set                                                               *
+ *============================================================================*/
+
+// Feasible Set of a Problem
+type feasibleSet struct {
+	lower       []float32      // Lower Bound of Held Assets
+	upper       []float32      // Upper Bound of Held Assets
+	locked      []bool         // Locked Assets
+	mandatory   []bool         // Assets that Must be Held
+	excluded    []bool         // Excluded Assets
+	classes     map[int]Bounds // Per-Class Bounds
+	minHoldings int            // Minimum Number of Holdings
+	maxHoldings int            // Maximum Number of Holdings
+	budget      float32        // Allocation not Taken by Locked Positions
+}
+
+// Builds the feasible set of a problem.
+func (p *Problem) newFeasibleSet() (*feasibleSet, error) {
+	n := len(p.Assets)
+	c := p.Constraints
+
+	if c == nil {
+		c = NewConstraints()
+	}
+
+	fs := &feasibleSet{}
+	fs.lower = make([]float32, n)
+	fs.upper = make([]float32, n)
+	fs.locked = make([]bool, n)
+	fs.mandatory = make([]bool, n)
+	fs.excluded = make([]bool, n)
+	fs.classes = c.classes
+	fs.minHoldings = c.minHoldings
+	fs.maxHoldings = c.maxHoldings
+	fs.budget = 1.0
+
+	if fs.maxHoldings <= 0 {
+		fs.maxHoldings = n
+	}
+
+	// Index asset IDs.
+	index := make(map[int]int)
+	for i, a := range p.Assets {
+		index[a.ID()] = i
+	}
+
+	for assetID := range c.locks {
+		if _, ok := index[assetID]; !ok {
+			return nil, fmt.Errorf("locked asset is not in the problem")
+		}
+	}
+
+	fixed := float32(0.0)
+	numFixed := 0
+	numCandidates := 0
+	maxTotal := float32(0.0)
+	for i, a := range p.Assets {
+		fs.lower[i] = p.MinAllocation
+		fs.upper[i] = p.MaxAllocation
+
+		if b, ok := c.assets[a.ID()]; ok {
+			if b.Min > b.Max {
+				return nil, fmt.Errorf("invalid bounds for asset " + a.Ticker())
+			}
+			fs.lower[i] = b.Min
+			fs.upper[i] = b.Max
+			fs.mandatory[i] = b.Min > 0.0
+		}
+
+		if weight, ok := c.locks[a.ID()]; ok {
+			if weight < 0.0 {
+				return nil, fmt.Errorf("unresolved lock for asset " + a.Ticker())
+			}
+			fs.lower[i] = weight
+			fs.upper[i] = weight
+			fs.locked[i] = true
+			fs.mandatory[i] = weight > 0.0
+			fs.budget -= weight
+		}
+
+		// Poor data.
+		if a.Completeness() < c.completeness && !fs.locked[i] && !fs.mandatory[i] {
+			fs.excluded[i] = true
+			continue
+		}
+
+		if c.excluded[a.ID()] {
+			if fs.locked[i] || fs.mandatory[i] {
+				return nil, fmt.Errorf("asset " + a.Ticker() + " is both excluded and required")
+			}
+			fs.excluded[i] = true
+			continue
+		}
+
+		if fs.mandatory[i] {
+			numFixed++
+			fixed += fs.lower[i]
+		}
+		if !fs.locked[i] || fs.mandatory[i] {
+			numCandidates++
+		}
+		maxTotal += fs.upper[i]
+	}
+
+	// Check for trivially infeasible problems.
+	if fs.budget < -epsilon || fixed > 1.0+epsilon {
+		return nil, fmt.Errorf("locked and minimum allocations exceed 100%%")
+	}
+	if maxTotal < 1.0-epsilon {
+		return nil, fmt.Errorf("maximum allocations do not reach 100%%")
+	}
+	if numFixed > fs.maxHoldings {
+		return nil, fmt.Errorf("required holdings exceed the maximum number of holdings")
+	}
+	if numCandidates < fs.minHoldings || fs.minHoldings > fs.maxHoldings {
+		return nil, fmt.Errorf("cannot satisfy the minimum number of holdings")
+	}
+
+	return fs, nil
+}
+
+// Asserts if an allocation is feasible.
+func (fs *feasibleSet) feasible(p *Problem, w []float32) bool {
+	var total float32
+	holdings := 0
+
+	for i := range w {
+		if w[i] <= 0.0 {
+			if fs.mandatory[i] {
+				return false
+			}
+			continue
+		}
+
+		if fs.excluded[i] {
+			return false
+		}
+		if w[i] < fs.lower[i]-epsilon || w[i] > fs.upper[i]+epsilon {
+			return false
+		}
+
+		total += w[i]
+		holdings++
+	}
+
+	if total < 1.0-epsilon || total > 1.0+epsilon {
+		return false
+	}
+	if holdings < fs.minHoldings || holdings > fs.maxHoldings {
+		return false
+	}
+	for classID, b := range fs.classes {
+		var allocation float32
+		for i := range w {
+			if database.IsSubclass(p.Assets[i].Class(), classID) {
+				allocation += w[i]
+			}
+		}
+		if allocation < b.Min-epsilon || allocation > b.Max+epsilon {
+			return false
+		}
+	}
+
+	return true
+}
+
+/*============================================================================*
+ * Repair Operator                                                            *
+ *============================================================================*/
+
+// Shifts an amount of weight among held and free assets in a mask,
+// proportionally to their current weights and within their bounds. It
+// returns the amount actually shifted.
+func (fs *feasibleSet) shift(w []float32, mask func(int) bool, amount float32) float32 {
+	var applied float32
+
+	for iter := 0; iter < len(w); iter++ {
+		var total float32
+		remaining := amount - applied
+
+		if remaining > -epsilon/10 && remaining < epsilon/10 {
+			break
+		}
+
+		// Compute weights of assets with room.
+		for i := range w {
+			if w[i] <= 0.0 || fs.locked[i] || !mask(i) {
+				continue
+			}
+			if (remaining > 0.0 && w[i] < fs.upper[i]) ||
+				(remaining < 0.0 && w[i] > fs.lower[i]) {
+				total += w[i]
+			}
+		}
+
+		// No room left.
+		if total <= 0.0 {
+			break
+		}
+
+		for i := range w {
+			if w[i] <= 0.0 || fs.locked[i] || !mask(i) {
+				continue
+			}
+			if (remaining > 0.0 && w[i] < fs.upper[i]) ||
+				(remaining < 0.0 && w[i] > fs.lower[i]) {
+				x := w[i] + remaining*w[i]/total
+				if x > fs.upper[i] {
+					x = fs.upper[i]
+				} else if x < fs.lower[i] {
+					x = fs.lower[i]
+				}
+				applied += x - w[i]
+				w[i] = x
+			}
+		}
+	}
+
+	return applied
+}
+
+// Enforces the bounds on the number of holdings.
+func (fs *feasibleSet) enforceHoldings(p *Problem, w []float32) {
+	held := make([]int, 0)
+
+	for i := range w {
+		if w[i] > 0.0 {
+			held = append(held, i)
+		}
+	}
+
+	// Too many holdings: drop smallest positions.
+	if len(held) > fs.maxHoldings {
+		numHeld := len(held)
+		sort.Slice(held, func(a, b int) bool { return w[held[a]] < w[held[b]] })
+		for _, i := range held {
+			if numHeld <= fs.maxHoldings {
+				break
+			}
+			if !fs.mandatory[i] && !fs.locked[i] {
+				w[i] = 0.0
+				numHeld--
+			}
+		}
+	}
+
+	// Too few holdings: pick random assets.
+	for numHeld := len(held); numHeld < fs.minHoldings; {
+		i := p.Rand.Intn(len(w))
+		if w[i] <= 0.0 && !fs.excluded[i] && !fs.locked[i] {
+			w[i] = fs.lower[i]
+			if w[i] <= 0.0 {
+				w[i] = DefaultMinAllocation
+			}
+			numHeld++
+		}
+	}
+}
+
+// Starts holding the first free asset in a mask, if the maximum number of
+// holdings allows.
+func (fs *feasibleSet) activate(w []float32, mask func(int) bool) {
+	numHeld := 0
+
+	for i := range w {
+		if w[i] > 0.0 {
+			numHeld++
+		}
+	}
+
+	if numHeld >= fs.maxHoldings {
+		return
+	}
+
+	for i := range w {
+		if w[i] <= 0.0 && !fs.excluded[i] && !fs.locked[i] && mask(i) {
+			w[i] = fs.lower[i]
+			if w[i] <= 0.0 {
+				w[i] = DefaultMinAllocation
+			}
+			return
+		}
+	}
+}
+
+// Enforces per-class bounds.
+func (fs *feasibleSet) enforceClasses(p *Problem, w []float32) {
+	for classID, b := range fs.classes {
+		var total float32
+
+		member := func(i int) bool { return database.IsSubclass(p.Assets[i].Class(), classID) }
+		other := func(i int) bool { return !database.IsSubclass(p.Assets[i].Class(), classID) }
+
+		for i := range w {
+			if member(i) {
+				total += w[i]
+			}
+		}
+
+		if total > b.Max {
+			moved := fs.shift(w, member, b.Max-total)
+			fs.shift(w, other, -moved)
+		} else if total < b.Min {
+
+			// No holdings in this class: pick one.
+			if total <= 0.0 {
+				fs.activate(w, member)
+			}
+
+			moved := fs.shift(w, member, b.Min-total)
+			fs.shift(w, other, -moved)
+		}
+	}
+}
+
+// Repairs an allocation so that it satisfies the constraints of a problem.
+func (p *Problem) repair(w []float32) {
+	var free float32
+	fs := p.feasible
+
+	// Fixed positions.
+	for i := range w {
+		if fs.excluded[i] {
+			w[i] = 0.0
+		} else if fs.locked[i] {
+			w[i] = fs.lower[i]
+		} else if fs.mandatory[i] && w[i] < fs.lower[i] {
+			w[i] = fs.lower[i]
+		}
+	}
+
+	// Scale free positions to the available budget.
+	for i := range w {
+		if !fs.locked[i] {
+			free += w[i]
+		}
+	}
+	if free > 0.0 {
+		for i := range w {
+			if !fs.locked[i] {
+				w[i] *= fs.budget / free
+			}
+		}
+	}
+
+	// Drop positions that are too small.
+	for i := range w {
+		if !fs.locked[i] && !fs.mandatory[i] && w[i] < fs.lower[i] {
+			w[i] = 0.0
+		}
+	}
+
+	fs.enforceHoldings(p, w)
+
+	all := func(i int) bool { return true }
+	for pass := 0; pass < maxRepairPasses; pass++ {
+		var total float32
+
+		// Clip to bounds.
+		for i := range w {
+			if w[i] > fs.upper[i] {
+				w[i] = fs.upper[i]
+			} else if w[i] > 0.0 && w[i] < fs.lower[i] {
+				w[i] = fs.lower[i]
+			}
+			total += w[i]
+		}
+
+		fs.shift(w, all, 1.0-total)
+		fs.enforceClasses(p, w)
+
+		if fs.feasible(p, w) {
+			break
+		}
+	}
+}
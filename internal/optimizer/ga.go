@@ -0,0 +1,331 @@
+/*
+ * MIT License
+ *
+ * Copyright(c) 2020 Pedro Henrique Penna <pedrohenriquepenna@gmail.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package optimizer
+
+import (
+	"fmt"
+	"sort"
+)
+
+// GA Configuration
+const (
+	populationSize  = 5000  // Population Size
+	selectionRatio  = 0.60  // Selection Ratio
+	eliteRatio      = 0.05  // Elite Ratio
+	mutationRatio   = 0.02  // Mutation Ratio
+	evolutionCutOff = 1000  // GA Evolution Cut Off
+	maxGenerations  = 10000 // Maximum Number of Generations
+	maxAttempts     = 10    // Attempts to Sample a Feasible Gene (per gene)
+)
+
+/*============================================================================*
+ * Gene                                                                       *
+ *============================================================================*/
+
+// Gene
+type gene struct {
+	dna        []float32
+	fitness    float32   // Fitness
+	objectives []float32 // Objective Values (multi-objective mode)
+	feasible   bool      // Feasible?
+	rank       int       // Non-Domination Rank (multi-objective mode)
+	crowding   float32   // Crowding Distance (multi-objective mode)
+}
+
+// Normalizes a gene.
+func (g *gene) normalize() {
+	norm := float32(0.0)
+
+	for i := 0; i < len(g.dna); i++ {
+		norm += g.dna[i]
+	}
+
+	if norm > 0.0 {
+		for i := 0; i < len(g.dna); i++ {
+			g.dna[i] /= norm
+		}
+	}
+}
+
+// Creates a new gene.
+func newGene(p *Problem) *gene {
+	g := &gene{}
+
+	g.dna = make([]float32, len(p.Assets))
+	for i := range g.dna {
+		g.dna[i] = p.Rand.Float32()
+	}
+
+	g.normalize()
+	p.repair(g.dna)
+
+	return g
+}
+
+// Evaluates the fitness of a gene. Infeasible genes are not evaluated, and
+// should be dropped.
+func (g *gene) eval(p *Problem) {
+	g.feasible = p.feasible.feasible(p, g.dna)
+	if !g.feasible {
+		return
+	}
+
+	g.fitness = p.Objective.Eval(p, g.dna)
+}
+
+// Cross overs two genes: the first half of the child comes from the first
+// gene, and the second half from the second one.
+func crossover(p *Problem, g1, g2 *gene) *gene {
+
+	g := &gene{}
+	g.dna = make([]float32, len(p.Assets))
+
+	point := len(g.dna) / 2
+
+	for i := 0; i < point; i++ {
+		g.dna[i] = g1.dna[i]
+	}
+
+	for i := point; i < len(g.dna); i++ {
+		g.dna[i] = g2.dna[i]
+	}
+
+	g.normalize()
+	p.repair(g.dna)
+
+	return g
+}
+
+// Mutates a gene.
+func (g *gene) mutate(p *Problem) {
+	point := p.Rand.Int31n(int32(len(g.dna)))
+
+	g.dna[point] = p.Rand.Float32()
+
+	g.normalize()
+	p.repair(g.dna)
+}
+
+/*============================================================================*
+ * Genetic Algorithm                                                          *
+ *============================================================================*/
+
+// Genetic Algorithms
+type GeneticAlgorithm struct {
+	populationSize int     // Population Size
+	selectionSize  int     // Selection Ratio
+	eliteSize      int     // Elite Ratio
+	mutationRatio  float32 // Mutation Ratio
+	verbose        bool    // Verbose Mode?
+}
+
+// Instantiates a new genetic algorithm
+func NewGeneticAlgorithm(popSize int, sRatio, eRatio, mRatio float32) *GeneticAlgorithm {
+	ga := &GeneticAlgorithm{}
+
+	ga.populationSize = popSize
+	ga.selectionSize = int(sRatio * float32(ga.populationSize))
+	ga.eliteSize = int(eRatio * float32(ga.populationSize))
+	ga.mutationRatio = mRatio
+
+	return ga
+}
+
+// Instantiates a genetic algorithm with the default configuration.
+func NewDefaultGeneticAlgorithm() *GeneticAlgorithm {
+	return NewGeneticAlgorithm(populationSize,
+		selectionRatio,
+		eliteRatio,
+		mutationRatio,
+	)
+}
+
+// Enables or disables verbose mode.
+func (ga *GeneticAlgorithm) SetVerbose(verbose bool) {
+	ga.verbose = verbose
+}
+
+// Selects organisms to mate, with a probability that grows with their fitness.
+// Fitness may be negative, so it is shifted by the worst fitness in the
+// population, and the worst gene keeps a small chance of being selected.
+func (ga *GeneticAlgorithm) selection(p *Problem, population []*gene) []*gene {
+
+	parents := make([]*gene, 0, ga.selectionSize)
+
+	worst, best := population[0].fitness, population[0].fitness
+	for _, g := range population {
+		if g.fitness < worst {
+			worst = g.fitness
+		}
+		if g.fitness > best {
+			best = g.fitness
+		}
+	}
+
+	// Same fitness: select uniformly.
+	offset := (best - worst) / float32(len(population))
+	if offset <= 0.0 {
+		offset = 1.0
+	}
+
+	totalFitness := float32(0.0)
+	for _, g := range population {
+		totalFitness += g.fitness - worst + offset
+	}
+
+	for i := 0; i < ga.selectionSize; i++ {
+		f := p.Rand.Float32() * totalFitness
+
+		// Fall back to the last gene on rounding errors.
+		selected := population[len(population)-1]
+		for _, g := range population {
+			f -= g.fitness - worst + offset
+
+			// Found.
+			if f <= 0 {
+				selected = g
+				break
+			}
+		}
+
+		parents = append(parents, selected)
+	}
+
+	return parents
+}
+
+// Breed new genes.
+func (ga *GeneticAlgorithm) breed(p *Problem, parents []*gene) []*gene {
+	children := make([]*gene, 0)
+
+	for i := 0; i < len(parents)-2; i += 2 {
+		child := crossover(p, parents[i], parents[i+1])
+
+		children = append(children, child)
+	}
+
+	feasible := children[:0]
+	for _, g := range children {
+
+		if p.Rand.Float32() <= ga.mutationRatio {
+			g.mutate(p)
+		}
+
+		// Drop children that could not be repaired.
+		if g.eval(p); g.feasible {
+			feasible = append(feasible, g)
+		}
+	}
+
+	return feasible
+}
+
+// Replace old population.
+func (ga *GeneticAlgorithm) replace(p *Problem, population, children []*gene) {
+	for _, child := range children {
+		i := p.Rand.Int31n(int32(ga.populationSize - ga.eliteSize))
+
+		population[i] = child
+	}
+}
+
+type ByFitness []*gene
+
+func (a ByFitness) Len() int           { return len(a) }
+func (a ByFitness) Less(i, j int) bool { return a[i].fitness < a[j].fitness }
+func (a ByFitness) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+
+// Runs the target genetic algorithm on an optimization problem.
+func (ga *GeneticAlgorithm) Optimize(p *Problem) (*Result, error) {
+
+	var bestGene *gene
+
+	p, err := p.prepare()
+	if err != nil {
+		return nil, err
+	}
+
+	genes := make([]*gene, 0, ga.populationSize)
+
+	// Generate initial population, with feasible genes only.
+	for i := 0; i < maxAttempts*ga.populationSize && len(genes) < ga.populationSize; i++ {
+		g := newGene(p)
+		if g.eval(p); g.feasible {
+			genes = append(genes, g)
+		}
+	}
+	if len(genes) == 0 {
+		return nil, fmt.Errorf("no feasible allocation found")
+	}
+
+	// Few feasible genes: fill the population with copies.
+	for i := 0; len(genes) < ga.populationSize; i++ {
+		g := &gene{}
+		g.dna = append([]float32(nil), genes[i].dna...)
+		g.fitness = genes[i].fitness
+		g.feasible = true
+		genes = append(genes, g)
+	}
+	sort.Sort(ByFitness(genes))
+
+	bestGene = genes[len(genes)-1]
+
+	if ga.verbose {
+		fmt.Println("Running Genetic Algorithm...")
+	}
+
+	generations := 1
+	lastGeneration := evolutionCutOff
+	for i := 1; i < maxGenerations; i++ {
+		generations = i + 1
+
+		parents := ga.selection(p, genes)
+
+		children := ga.breed(p, parents)
+		ga.replace(p, genes, children)
+		sort.Sort(ByFitness(genes))
+
+		if genes[len(genes)-1].fitness > bestGene.fitness {
+			bestGene = genes[len(genes)-1]
+
+			lastGeneration = i + evolutionCutOff
+
+			if ga.verbose {
+				fmt.Printf("%4d Best Fitness: %f\n", i, bestGene.fitness)
+
+			}
+		}
+
+		// Stable solution.
+		if i >= lastGeneration {
+			break
+		}
+	}
+
+	result := p.newResult(bestGene.dna, bestGene.fitness)
+	result.Generations = generations
+
+	return result, nil
+}
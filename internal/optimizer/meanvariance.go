@@ -0,0 +1,356 @@
+/*
+ * MIT License
+ *
+ * Copyright(c) 2020 Pedro Henrique Penna <pedrohenriquepenna@gmail.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package optimizer
+
+import (
+	"fmt"
+	"math"
+	"portfolio/internal/covariance"
+)
+
+// Mean-Variance Modes
+const (
+	MinVariance  = iota // Minimum Variance Portfolio
+	MaxSharpe           // Maximum Sharpe Ratio Portfolio
+	TargetReturn        // Minimum Variance Portfolio for a Target Return
+)
+
+// Mean-Variance Solver Configuration
+const (
+	mvMaxIterations = 5000  // Maximum Number of Iterations
+	mvTolerance     = 1e-10 // Convergence Tolerance
+	mvSharpeSteps   = 80    // Number of Points Scanned in the Frontier
+	mvMaxDoublings  = 64    // Maximum Number of Doublings to Bracket a Projection
+)
+
+// Mean-Variance (Markowitz) Optimizer
+type MeanVariance struct {
+	mode         int     // Mode
+	targetReturn float64 // Target Annual Return
+	riskFree     float64 // Annual Risk-Free Rate
+}
+
+// Instantiates a mean-variance optimizer. The target return is only used in
+// TargetReturn mode, and the risk-free rate only in MaxSharpe mode.
+func NewMeanVariance(mode int, targetReturn, riskFree float32) *MeanVariance {
+	mv := &MeanVariance{}
+
+	mv.mode = mode
+	mv.targetReturn = float64(targetReturn)
+	mv.riskFree = float64(riskFree)
+
+	return mv
+}
+
+/*============================================================================*
+ * Solver                                                                     *
+ *============================================================================*/
+
+// Box-Constrained Simplex
+type simplex struct {
+	lower  []float64 // Lower Bounds
+	upper  []float64 // Upper Bounds
+	budget float64   // Sum of Weights
+}
+
+// Asserts that the bounds of the simplex admit weights that sum to its budget.
+func (s *simplex) validate() error {
+	var lower, upper float64
+
+	for i := range s.lower {
+		lower += s.lower[i]
+		upper += s.upper[i]
+	}
+
+	if lower > s.budget+epsilon {
+		return fmt.Errorf("minimum allocations exceed 100%%")
+	}
+	if upper < s.budget-epsilon {
+		return fmt.Errorf("maximum allocations do not reach 100%%")
+	}
+
+	return nil
+}
+
+// Projects a point onto the simplex. Bounds are enforced even if the simplex
+// is empty, in which case weights do not sum to the budget.
+func (s *simplex) project(v []float64, w []float64) {
+	clamp := func(tau float64) float64 {
+		var sum float64
+		for i := range v {
+			w[i] = math.Max(s.lower[i], math.Min(s.upper[i], v[i]-tau))
+			sum += w[i]
+		}
+		return sum
+	}
+
+	// Bracket the shift.
+	lo, hi := -1.0, 1.0
+	for k := 0; k < mvMaxDoublings && clamp(lo) < s.budget; k++ {
+		lo *= 2
+	}
+	for k := 0; k < mvMaxDoublings && clamp(hi) > s.budget; k++ {
+		hi *= 2
+	}
+
+	// Bisect.
+	for iter := 0; iter < 100; iter++ {
+		mid := (lo + hi) / 2
+		if clamp(mid) > s.budget {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	clamp(hi)
+}
+
+// Minimizes w'Cw - lambda * mu'w over the simplex, by projected gradient.
+func (s *simplex) solve(stats *covariance.Matrix, lambda float64) []float64 {
+	n := stats.Size()
+
+	// Step size from the Lipschitz constant of the gradient.
+	var lipschitz float64
+	for i := 0; i < n; i++ {
+		var row float64
+		for j := 0; j < n; j++ {
+			row += math.Abs(stats.Cov(i, j))
+		}
+		lipschitz = math.Max(lipschitz, 2*row)
+	}
+
+	// Zero covariances: the objective is linear, so any step converges.
+	step := 1.0
+	if lipschitz > 0.0 {
+		step = 1.0 / lipschitz
+	}
+
+	w := make([]float64, n)
+	v := make([]float64, n)
+	next := make([]float64, n)
+
+	// Start from the equally weighted portfolio.
+	for i := range v {
+		v[i] = s.budget / float64(n)
+	}
+	s.project(v, w)
+
+	for iter := 0; iter < mvMaxIterations; iter++ {
+		var delta float64
+
+		for i := 0; i < n; i++ {
+			var grad float64
+			for j := 0; j < n; j++ {
+				grad += 2 * stats.Cov(i, j) * w[j]
+			}
+			grad -= lambda * stats.Mean(i)
+			v[i] = w[i] - step*grad
+		}
+		s.project(v, next)
+
+		for i := range w {
+			delta += (next[i] - w[i]) * (next[i] - w[i])
+			w[i] = next[i]
+		}
+
+		// Converged.
+		if delta < mvTolerance*mvTolerance {
+			break
+		}
+	}
+
+	return w
+}
+
+// Solves the mean-variance problem for the current mode.
+func (mv *MeanVariance) solve(stats *covariance.Matrix, s *simplex) ([]float64, error) {
+
+	switch mv.mode {
+
+	case MinVariance:
+		return s.solve(stats, 0.0), nil
+
+	case TargetReturn:
+		w := s.solve(stats, 0.0)
+		if stats.PortfolioReturn(w) >= mv.targetReturn {
+			return w, nil
+		}
+
+		// Find an upper bound for the risk aversion.
+		hi := 1.0
+		for ; hi < 1e6; hi *= 2 {
+			if stats.PortfolioReturn(s.solve(stats, hi)) >= mv.targetReturn {
+				break
+			}
+		}
+		if hi >= 1e6 {
+			return nil, fmt.Errorf("target return is not achievable")
+		}
+
+		// Bisect.
+		lo := 0.0
+		for iter := 0; iter < 50; iter++ {
+			mid := (lo + hi) / 2
+			if stats.PortfolioReturn(s.solve(stats, mid)) >= mv.targetReturn {
+				hi = mid
+			} else {
+				lo = mid
+			}
+		}
+
+		return s.solve(stats, hi), nil
+
+	case MaxSharpe:
+		var best []float64
+		bestSharpe := math.Inf(-1)
+
+		// Scan the efficient frontier.
+		for k := 0; k <= mvSharpeSteps; k++ {
+			lambda := 0.0
+			if k > 0 {
+				lambda = math.Pow(10, -3+6*float64(k-1)/float64(mvSharpeSteps-1))
+			}
+
+			w := s.solve(stats, lambda)
+			volatility := math.Sqrt(stats.PortfolioVariance(w))
+			if volatility <= 0.0 {
+				continue
+			}
+
+			sharpe := (stats.PortfolioReturn(w) - mv.riskFree) / volatility
+			if sharpe > bestSharpe {
+				best = w
+				bestSharpe = sharpe
+			}
+		}
+
+		if best == nil {
+			return nil, fmt.Errorf("cannot compute sharpe ratio")
+		}
+
+		return best, nil
+	}
+
+	return nil, fmt.Errorf("unknown mean-variance mode")
+}
+
+// Asserts if two slices are equal.
+func equalSlices(a, b []float64) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+/*============================================================================*
+ * Optimize()                                                                 *
+ *============================================================================*/
+
+// Solves an optimization problem using mean-variance analysis. Assets
+// whose weights fall below the minimum allocation are dropped and the problem
+// is solved again, until all held assets satisfy their bounds. Class and
+// cardinality constraints are enforced afterwards by the repair operator.
+func (mv *MeanVariance) Optimize(p *Problem) (*Result, error) {
+
+	p, err := p.prepare()
+	if err != nil {
+		return nil, err
+	}
+
+	// Reuse the covariance matrix of the risk terms, if any.
+	stats := p.covariance
+	if stats == nil {
+		if stats, err = covariance.Estimate(p.Assets); err != nil {
+			return nil, err
+		}
+	}
+
+	fs := p.feasible
+	n := len(p.Assets)
+
+	s := &simplex{}
+	s.lower = make([]float64, n)
+	s.upper = make([]float64, n)
+	s.budget = 1.0
+	for i := 0; i < n; i++ {
+		if fs.mandatory[i] || fs.locked[i] {
+			s.lower[i] = float64(fs.lower[i])
+		}
+		s.upper[i] = float64(fs.upper[i])
+		if fs.excluded[i] {
+			s.upper[i] = 0.0
+		}
+	}
+
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+
+	var w []float64
+	for {
+		if w, err = mv.solve(stats, s); err != nil {
+			return nil, err
+		}
+
+		// Drop positions that are too small.
+		var capacity float64
+		upper := make([]float64, n)
+		copy(upper, s.upper)
+		for i := range w {
+			if w[i] > 1e-6 && w[i] < float64(fs.lower[i])-epsilon && s.lower[i] == 0.0 {
+				upper[i] = 0.0
+			}
+			capacity += upper[i]
+		}
+
+		// Nothing to drop, or dropping leads to an infeasible problem.
+		if capacity < s.budget || equalSlices(upper, s.upper) {
+			break
+		}
+		s.upper = upper
+	}
+
+	weights := make([]float32, n)
+	for i := range w {
+		if w[i] > 1e-6 {
+			weights[i] = float32(w[i])
+		}
+	}
+	p.repair(weights)
+
+	w = make([]float64, n)
+	for i := range weights {
+		w[i] = float64(weights[i])
+	}
+
+	result := p.newResult(weights, p.Objective.Eval(p, weights))
+	result.ExpectedReturn = float32(stats.PortfolioReturn(w))
+	result.Volatility = float32(math.Sqrt(stats.PortfolioVariance(w)))
+
+	return result, nil
+}
@@ -0,0 +1,451 @@
+/*
+ * MIT License
+ *
+ * Copyright(c) 2020 Pedro Henrique Penna <pedrohenriquepenna@gmail.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package optimizer
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"portfolio/internal/asset"
+	"sort"
+)
+
+// NSGA-II Configuration
+const (
+	nsgaPopulationSize = 200 // Population Size
+	nsgaGenerations    = 250 // Number of Generations
+	nsgaMutationRatio  = 0.2 // Mutation Ratio
+)
+
+// Components of the multi-objective evaluation (all maximized).
+var Components = []string{"Cost", "Performance", "Risk"}
+
+// Component evaluators, in the same order as Components.
+var componentsEval = []func(*Problem, []float32) float32{
+	costEval,
+	perfEval,
+	riskEval,
+}
+
+/*============================================================================*
+ * Pareto Front                                                               *
+ *============================================================================*/
+
+// Solution of a Multi-Objective Problem
+type Solution struct {
+	Weights    []float32       // Weights (indexed as the assets of the problem)
+	Allocation map[int]float32 // Allocation (indexed by asset ID)
+	Objectives []float32       // Objective Values (indexed as Components)
+}
+
+// Pareto Front
+type ParetoFront struct {
+	assets    []*asset.Asset // Assets
+	Solutions []*Solution    // Non-Dominated Solutions
+}
+
+// Writes a Pareto front into a file.
+func (front *ParetoFront) Write(file *os.File) error {
+
+	// Invalid file.
+	if file == nil {
+		return fmt.Errorf("invalid file")
+	}
+
+	fmt.Fprintf(file, "\nPareto Front (%d solutions)\n", len(front.Solutions))
+	fmt.Fprintf(file, "  %4s", "#")
+	for _, name := range Components {
+		fmt.Fprintf(file, " %12s", name)
+	}
+	fmt.Fprintf(file, "  %s\n", "Holdings")
+
+	for i, s := range front.Solutions {
+		fmt.Fprintf(file, "  %4d", i)
+		for _, value := range s.Objectives {
+			fmt.Fprintf(file, " %10.2f %%", 100*value)
+		}
+		fmt.Fprintf(file, " ")
+		for j, a := range front.assets {
+			if s.Weights[j] > 0.0 {
+				fmt.Fprintf(file, " %s:%.2f", a.Ticker(), 100*s.Weights[j])
+			}
+		}
+		fmt.Fprintf(file, "\n")
+	}
+
+	return nil
+}
+
+// Exports a Pareto front to a CSV file.
+func (front *ParetoFront) Persist(filename string) error {
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	// Header.
+	for _, name := range Components {
+		fmt.Fprintf(file, "%s,", name)
+	}
+	for j, a := range front.assets {
+		if j > 0 {
+			fmt.Fprintf(file, ",")
+		}
+		fmt.Fprintf(file, "%s", a.Ticker())
+	}
+	fmt.Fprintf(file, "\n")
+
+	// Solutions.
+	for _, s := range front.Solutions {
+		for _, value := range s.Objectives {
+			fmt.Fprintf(file, "%f,", value)
+		}
+		for j := range front.assets {
+			if j > 0 {
+				fmt.Fprintf(file, ",")
+			}
+			fmt.Fprintf(file, "%.4f", s.Weights[j])
+		}
+		fmt.Fprintf(file, "\n")
+	}
+
+	return nil
+}
+
+/*============================================================================*
+ * NSGA-II                                                                    *
+ *============================================================================*/
+
+// Non-Dominated Sorting Genetic Algorithm II
+type NSGA2 struct {
+	populationSize int     // Population Size
+	generations    int     // Number of Generations
+	mutationRatio  float32 // Mutation Ratio
+	verbose        bool    // Verbose Mode?
+}
+
+// Instantiates a new NSGA-II.
+func NewNSGA2(popSize, generations int, mRatio float32) *NSGA2 {
+	nsga := &NSGA2{}
+
+	nsga.populationSize = popSize
+	nsga.generations = generations
+	nsga.mutationRatio = mRatio
+
+	return nsga
+}
+
+// Instantiates a NSGA-II with the default configuration.
+func NewDefaultNSGA2() *NSGA2 {
+	return NewNSGA2(nsgaPopulationSize, nsgaGenerations, nsgaMutationRatio)
+}
+
+// Enables or disables verbose mode.
+func (nsga *NSGA2) SetVerbose(verbose bool) {
+	nsga.verbose = verbose
+}
+
+// Evaluates all components of a gene.
+func (g *gene) evalComponents(p *Problem) {
+	g.feasible = p.feasible.feasible(p, g.dna)
+
+	g.objectives = make([]float32, len(componentsEval))
+	for i, eval := range componentsEval {
+		g.objectives[i] = eval(p, g.dna)
+	}
+}
+
+// Asserts if a gene dominates another one. Feasible genes dominate
+// infeasible ones.
+func (g *gene) dominates(other *gene) bool {
+
+	if g.feasible != other.feasible {
+		return g.feasible
+	}
+
+	better := false
+	for i := range g.objectives {
+		if g.objectives[i] < other.objectives[i] {
+			return false
+		}
+		if g.objectives[i] > other.objectives[i] {
+			better = true
+		}
+	}
+
+	return better
+}
+
+// Blends two genes.
+func blend(p *Problem, g1, g2 *gene) *gene {
+	g := &gene{}
+	g.dna = make([]float32, len(p.Assets))
+
+	alpha := p.Rand.Float32()
+	for i := range g.dna {
+		g.dna[i] = alpha*g1.dna[i] + (1.0-alpha)*g2.dna[i]
+	}
+
+	g.normalize()
+	p.repair(g.dna)
+
+	return g
+}
+
+// Sorts a population into non-dominated fronts.
+func nonDominatedSort(population []*gene) [][]*gene {
+	fronts := make([][]*gene, 0)
+	dominated := make([][]int, len(population))
+	counter := make([]int, len(population))
+
+	current := make([]int, 0)
+	for i := range population {
+		for j := range population {
+			if population[i].dominates(population[j]) {
+				dominated[i] = append(dominated[i], j)
+			} else if population[j].dominates(population[i]) {
+				counter[i]++
+			}
+		}
+
+		if counter[i] == 0 {
+			population[i].rank = 0
+			current = append(current, i)
+		}
+	}
+
+	for rank := 0; len(current) > 0; rank++ {
+		front := make([]*gene, 0, len(current))
+		next := make([]int, 0)
+
+		for _, i := range current {
+			front = append(front, population[i])
+			for _, j := range dominated[i] {
+				counter[j]--
+				if counter[j] == 0 {
+					population[j].rank = rank + 1
+					next = append(next, j)
+				}
+			}
+		}
+
+		fronts = append(fronts, front)
+		current = next
+	}
+
+	return fronts
+}
+
+// Assigns crowding distances to the genes of a front.
+func crowdingDistance(front []*gene) {
+	for _, g := range front {
+		g.crowding = 0.0
+	}
+
+	for m := range componentsEval {
+		sort.Slice(front, func(a, b int) bool {
+			return front[a].objectives[m] < front[b].objectives[m]
+		})
+
+		first := front[0]
+		last := front[len(front)-1]
+		first.crowding = float32(math.Inf(1))
+		last.crowding = float32(math.Inf(1))
+
+		span := last.objectives[m] - first.objectives[m]
+		if span <= 0.0 {
+			continue
+		}
+
+		for i := 1; i < len(front)-1; i++ {
+			d := front[i+1].objectives[m] - front[i-1].objectives[m]
+			front[i].crowding += d / span
+		}
+	}
+}
+
+// Asserts if a gene is preferred over another one (crowded comparison).
+func (g *gene) preferred(other *gene) bool {
+	if g.rank != other.rank {
+		return g.rank < other.rank
+	}
+
+	return g.crowding > other.crowding
+}
+
+// Selects a gene by binary tournament.
+func (nsga *NSGA2) tournament(p *Problem, population []*gene) *gene {
+	g1 := population[p.Rand.Intn(len(population))]
+	g2 := population[p.Rand.Intn(len(population))]
+
+	if g2.preferred(g1) {
+		return g2
+	}
+
+	return g1
+}
+
+// Breeds offspring.
+func (nsga *NSGA2) breed(p *Problem, population []*gene) []*gene {
+	children := make([]*gene, nsga.populationSize)
+
+	for i := range children {
+		parent1 := nsga.tournament(p, population)
+		parent2 := nsga.tournament(p, population)
+
+		children[i] = blend(p, parent1, parent2)
+
+		if p.Rand.Float32() <= nsga.mutationRatio {
+			children[i].mutate(p)
+		}
+
+		children[i].evalComponents(p)
+	}
+
+	return children
+}
+
+// Selects the next population out of parents and offspring.
+func (nsga *NSGA2) survival(population []*gene) []*gene {
+	next := make([]*gene, 0, nsga.populationSize)
+
+	for _, front := range nonDominatedSort(population) {
+		crowdingDistance(front)
+
+		// Whole front fits.
+		if len(next)+len(front) <= nsga.populationSize {
+			next = append(next, front...)
+			continue
+		}
+
+		// Truncate front by crowding distance.
+		sort.Slice(front, func(a, b int) bool {
+			return front[a].crowding > front[b].crowding
+		})
+		next = append(next, front[:nsga.populationSize-len(next)]...)
+		break
+	}
+
+	return next
+}
+
+// Computes the Pareto front of an optimization problem.
+func (nsga *NSGA2) Front(p *Problem) (*ParetoFront, error) {
+
+	p, err := p.prepare()
+	if err != nil {
+		return nil, err
+	}
+
+	return nsga.front(p)
+}
+
+// Computes the Pareto front of a prepared optimization problem.
+func (nsga *NSGA2) front(p *Problem) (*ParetoFront, error) {
+
+	// Generate initial population.
+	population := make([]*gene, nsga.populationSize)
+	for i := range population {
+		population[i] = newGene(p)
+		population[i].evalComponents(p)
+	}
+	population = nsga.survival(population)
+
+	if nsga.verbose {
+		fmt.Println("Running NSGA-II...")
+	}
+
+	for i := 1; i <= nsga.generations; i++ {
+		children := nsga.breed(p, population)
+		population = nsga.survival(append(population, children...))
+
+		if nsga.verbose && i%50 == 0 {
+			fmt.Printf("%4d Front Size: %d\n", i, len(nonDominatedSort(population)[0]))
+		}
+	}
+
+	// Extract non-dominated and feasible solutions.
+	front := &ParetoFront{}
+	front.assets = p.Assets
+	front.Solutions = make([]*Solution, 0)
+	for _, g := range nonDominatedSort(population)[0] {
+		if !g.feasible {
+			continue
+		}
+
+		s := &Solution{}
+		s.Weights = g.dna
+		s.Objectives = g.objectives
+		s.Allocation = make(map[int]float32)
+		for i, a := range p.Assets {
+			s.Allocation[a.ID()] = g.dna[i]
+		}
+		front.Solutions = append(front.Solutions, s)
+	}
+
+	if len(front.Solutions) == 0 {
+		return nil, fmt.Errorf("no feasible solution found")
+	}
+
+	// Sort solutions by the first component.
+	sort.Slice(front.Solutions, func(a, b int) bool {
+		return front.Solutions[a].Objectives[0] < front.Solutions[b].Objectives[0]
+	})
+
+	return front, nil
+}
+
+// Computes the Pareto front of an optimization problem and picks the
+// solution in the front that best fits the objective function of the problem.
+func (nsga *NSGA2) Optimize(p *Problem) (*Result, error) {
+
+	// Score solutions with the covariance-based risk terms as well.
+	p, err := p.prepare()
+	if err != nil {
+		return nil, err
+	}
+
+	front, err := nsga.front(p)
+	if err != nil {
+		return nil, err
+	}
+
+	best := front.Solutions[0]
+	bestFitness := p.Objective.Eval(p, best.Weights)
+	for _, s := range front.Solutions[1:] {
+		if fitness := p.Objective.Eval(p, s.Weights); fitness > bestFitness {
+			best = s
+			bestFitness = fitness
+		}
+	}
+
+	result := p.newResult(best.Weights, bestFitness)
+	result.Generations = nsga.generations
+	result.Front = front
+
+	return result, nil
+}
@@ -0,0 +1,246 @@
+/*
+ * MIT License
+ *
+ * Copyright(c) 2020 Pedro Henrique Penna <pedrohenriquepenna@gmail.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package optimizer
+
+import (
+	"fmt"
+	"portfolio/internal/database"
+	"sort"
+)
+
+// Objective Function
+type Objective interface {
+	// Evaluates an allocation for a given problem.
+	Eval(p *Problem, allocation []float32) float32
+}
+
+// Objective Weights
+type Weights struct {
+	Cost        float32 // Weight of Cost
+	Performance float32 // Weight of Performance
+	Risk        float32 // Weight of Risk
+	Data        float32 // Weight of the Penalty on Incomplete Data
+}
+
+// Weights of Covariance-Based Risk Terms
+type RiskTerms struct {
+	Volatility      float32 // Weight of the Portfolio Volatility
+	Diversification float32 // Weight of the Diversification Ratio
+	Bets            float32 // Weight of the Effective Number of Bets
+}
+
+// Asserts whether any covariance-based risk term is enabled.
+func (t RiskTerms) enabled() bool {
+	return t.Volatility != 0.0 || t.Diversification != 0.0 || t.Bets != 0.0
+}
+
+// Default objective weights.
+var DefaultWeights = Weights{Cost: 1.0, Performance: 1.0, Risk: 1.0}
+
+// Objective Factory
+type objectiveFactory func(w Weights) Objective
+
+// Known Objectives
+var objectivesDB = map[string]objectiveFactory{
+	"blend":       newBlendObjective,
+	"cost":        func(w Weights) Objective { return newBlendObjective(Weights{Cost: 1.0, Data: w.Data}) },
+	"performance": func(w Weights) Objective { return newBlendObjective(Weights{Performance: 1.0, Data: w.Data}) },
+	"risk":        func(w Weights) Objective { return newBlendObjective(Weights{Risk: 1.0, Data: w.Data}) },
+}
+
+// Instantiates an objective function given its name.
+func NewObjective(name string, w Weights) (Objective, error) {
+	factory, ok := objectivesDB[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown objective " + name)
+	}
+
+	return factory(w), nil
+}
+
+// Returns the names of known objective functions.
+func Objectives() []string {
+	names := make([]string, 0, len(objectivesDB))
+
+	for name := range objectivesDB {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+/*============================================================================*
+ * Evaluators                                                                 *
+ *============================================================================*/
+
+// Computes the cost valuation of an allocation.
+func costEval(p *Problem, allocation []float32) float32 {
+	value := float32(0.0)
+
+	for i := range allocation {
+		value += p.Assets[i].Cost() * allocation[i]
+	}
+
+	return value
+}
+
+// Computes the performance valuation of an allocation.
+func perfEval(p *Problem, allocation []float32) float32 {
+	performance := float32(0.0)
+
+	for i := range allocation {
+		performance += p.Assets[i].Performance() * allocation[i]
+	}
+
+	return performance
+}
+
+// Maps a risk onto a safety score in (0, 1], which is one for riskless
+// allocations.
+func safety(risk float32) float32 {
+	return 1.0 / (1.0 + risk)
+}
+
+// Computes the risk valuation of an allocation, from its diversification
+// across the classes of the taxonomy and its safety, both in [0, 0.1], plus
+// the enabled covariance-based risk terms. The safety accounts for the risk
+// of the assets and for the weighted volatility of the allocation.
+func riskEval(p *Problem, allocation []float32) float32 {
+	risk := float32(0.0)
+	classIDs := make([]int, len(allocation))
+
+	for i := range allocation {
+		classIDs[i] = p.Assets[i].Class()
+		risk += p.Assets[i].Risk(p.RiskWeights) * allocation[i]
+	}
+
+	if p.covariance != nil && p.RiskTerms.Volatility != 0.0 {
+		risk += p.RiskTerms.Volatility * float32(p.covariance.PortfolioVolatility(weights(allocation)))
+	}
+
+	value := (database.Diversification(classIDs, allocation) + safety(risk)) / 20.0
+
+	if p.covariance != nil {
+		value += covEval(p, allocation)
+	}
+
+	return value
+}
+
+// Converts an allocation to double precision.
+func weights(allocation []float32) []float64 {
+	w := make([]float64, len(allocation))
+	for i := range allocation {
+		w[i] = float64(allocation[i])
+	}
+
+	return w
+}
+
+// Computes the covariance-based diversification terms of an allocation: its
+// diversification ratio and effective number of bets are scored, like
+// diversification across classes, in [0, 0.1).
+func covEval(p *Problem, allocation []float32) float32 {
+	var value float64
+
+	w := weights(allocation)
+
+	if p.RiskTerms.Diversification != 0.0 {
+		if ratio := p.covariance.DiversificationRatio(w); ratio > 0.0 {
+			value += float64(p.RiskTerms.Diversification) * (1.0 - 1.0/ratio) / 10.0
+		}
+	}
+	if p.RiskTerms.Bets != 0.0 {
+		if bets := p.covariance.EffectiveBets(w); bets > 0.0 {
+			value += float64(p.RiskTerms.Bets) * (1.0 - 1.0/bets) / 10.0
+		}
+	}
+
+	return float32(value)
+}
+
+// Computes the penalty on an allocation for the incompleteness of the data of
+// its assets.
+func dataEval(p *Problem, allocation []float32) float32 {
+	penalty := float32(0.0)
+
+	for i := range allocation {
+		penalty += (p.Assets[i].Completeness() - 1.0) * allocation[i]
+	}
+
+	return penalty / 10.0
+}
+
+/*============================================================================*
+ * Blend Objective                                                            *
+ *============================================================================*/
+
+// Weighted blend of cost, performance and risk, plus a weighted penalty on
+// incomplete data.
+type blendObjective struct {
+	weights Weights // Weights
+	norm    float32 // Sum of Weights
+}
+
+// Instantiates a blend objective.
+func newBlendObjective(w Weights) Objective {
+	o := &blendObjective{}
+
+	o.weights = w
+	o.norm = w.Cost + w.Performance + w.Risk
+
+	// Fallback to default weights.
+	if o.norm <= 0.0 {
+		o.weights = DefaultWeights
+		o.weights.Data = w.Data
+		o.norm = 3.0
+	}
+
+	return o
+}
+
+// Evaluates an allocation.
+func (o *blendObjective) Eval(p *Problem, allocation []float32) float32 {
+	var value float32
+
+	if o.weights.Cost != 0.0 {
+		value += o.weights.Cost * costEval(p, allocation)
+	}
+	if o.weights.Performance != 0.0 {
+		value += o.weights.Performance * perfEval(p, allocation)
+	}
+	if o.weights.Risk != 0.0 {
+		value += o.weights.Risk * riskEval(p, allocation)
+	}
+
+	value /= o.norm
+
+	if o.weights.Data != 0.0 {
+		value += o.weights.Data * dataEval(p, allocation)
+	}
+
+	return value
+}
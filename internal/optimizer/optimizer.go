@@ -0,0 +1,149 @@
+/*
+ * MIT License
+ *
+ * Copyright(c) 2020 Pedro Henrique Penna <pedrohenriquepenna@gmail.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package optimizer
+
+import (
+	"fmt"
+	"math/rand"
+	"portfolio/internal/asset"
+	"portfolio/internal/covariance"
+)
+
+// Default Allocation Bounds
+const (
+	DefaultMinAllocation = 0.02 // Minimum Allocation for an Asset
+	DefaultMaxAllocation = 0.15 // Maximum Allocation for an Asset
+)
+
+// Optimization Problem
+type Problem struct {
+	Assets        []*asset.Asset    // Assets
+	Objective     Objective         // Objective Function
+	MinAllocation float32           // Minimum Allocation for an Asset
+	MaxAllocation float32           // Maximum Allocation for an Asset
+	Constraints   *Constraints      // Allocation Constraints (optional)
+	RiskWeights   asset.RiskWeights // Weights of Risk Measures of Assets
+	RiskTerms     RiskTerms         // Weights of Covariance-Based Risk Terms
+	Rand          *rand.Rand        // Random Number Generator
+
+	// Derived state, only set on the copy of a problem used by a run.
+	feasible   *feasibleSet       // Feasible Set
+	covariance *covariance.Matrix // Covariance Matrix (risk terms)
+}
+
+// Optimization Result
+type Result struct {
+	Weights     []float32       // Weights (indexed as the assets of the problem)
+	Allocation  map[int]float32 // Allocation (indexed by asset ID)
+	Fitness     float32         // Fitness
+	Generations int             // Number of Generations
+	Front       *ParetoFront    // Pareto Front (multi-objective mode)
+
+	ExpectedReturn float32 // Annualized Expected Return (mean-variance)
+	Volatility     float32 // Annualized Volatility (mean-variance)
+}
+
+// Optimizer
+type Optimizer interface {
+	// Solves an optimization problem.
+	Optimize(p *Problem) (*Result, error)
+}
+
+// Creates an optimization problem with default allocation bounds.
+func NewProblem(assets []*asset.Asset, objective Objective, rng *rand.Rand) *Problem {
+	p := &Problem{}
+
+	p.Assets = assets
+	p.Objective = objective
+	p.MinAllocation = DefaultMinAllocation
+	p.MaxAllocation = DefaultMaxAllocation
+	p.RiskWeights = asset.DefaultRiskWeights
+	p.Rand = rng
+
+	return p
+}
+
+// Asserts that an optimization problem is well formed and returns a copy of
+// it with its derived state, so that the problem itself is never modified and
+// may be shared by concurrent runs.
+func (p *Problem) prepare() (*Problem, error) {
+
+	if p == nil {
+		return nil, fmt.Errorf("invalid problem")
+	}
+
+	if len(p.Assets) == 0 {
+		return nil, fmt.Errorf("empty list of assets")
+	}
+
+	if p.Objective == nil {
+		return nil, fmt.Errorf("missing objective function")
+	}
+
+	if p.Rand == nil {
+		return nil, fmt.Errorf("missing random number generator")
+	}
+
+	if p.MinAllocation < 0.0 || p.MaxAllocation > 1.0 ||
+		p.MinAllocation > p.MaxAllocation {
+		return nil, fmt.Errorf("invalid allocation bounds")
+	}
+
+	if p.RiskTerms.Volatility < 0.0 || p.RiskTerms.Diversification < 0.0 ||
+		p.RiskTerms.Bets < 0.0 {
+		return nil, fmt.Errorf("negative risk term weight")
+	}
+
+	run := *p
+
+	fs, err := run.newFeasibleSet()
+	if err != nil {
+		return nil, err
+	}
+	run.feasible = fs
+
+	run.covariance = nil
+	if run.RiskTerms.enabled() {
+		if run.covariance, err = covariance.Estimate(run.Assets); err != nil {
+			return nil, err
+		}
+	}
+
+	return &run, nil
+}
+
+// Builds a result from a list of weights.
+func (p *Problem) newResult(weights []float32, fitness float32) *Result {
+	r := &Result{}
+
+	r.Weights = weights
+	r.Fitness = fitness
+	r.Allocation = make(map[int]float32)
+	for i, a := range p.Assets {
+		r.Allocation[a.ID()] = weights[i]
+	}
+
+	return r
+}
@@ -0,0 +1,270 @@
+/*
+ * MIT License
+ *
+ * Copyright(c) 2020 Pedro Henrique Penna <pedrohenriquepenna@gmail.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package optimizer
+
+import (
+	"math"
+	"math/rand"
+	"os"
+	"portfolio/internal/database"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+
+	// Paths are relative to the root of the repository.
+	if err := os.Chdir("../.."); err != nil {
+		panic(err.Error())
+	}
+	if err := database.Load("default.registry", "default.taxonomy"); err != nil {
+		panic(err.Error())
+	}
+
+	os.Exit(m.Run())
+}
+
+// Creates a blend problem on all known assets, with some risk terms.
+func newTestProblem(t *testing.T, terms RiskTerms) *Problem {
+	objective, err := NewObjective("blend", DefaultWeights)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := NewProblem(database.Assets(), objective, rand.New(rand.NewSource(1)))
+	p.MinAllocation = 0.0
+	p.MaxAllocation = 1.0
+	p.RiskTerms = terms
+
+	return p
+}
+
+func TestPrepare(t *testing.T) {
+	tests := []struct {
+		name  string    // Test Case
+		terms RiskTerms // Risk Terms
+		fails bool      // Expected Failure
+	}{
+		{"no risk terms", RiskTerms{}, false},
+		{"all risk terms", RiskTerms{Volatility: 1.0, Diversification: 1.0, Bets: 1.0}, false},
+		{"negative volatility", RiskTerms{Volatility: -1.0}, true},
+		{"negative bets", RiskTerms{Bets: -1.0}, true},
+	}
+
+	for _, test := range tests {
+		p := newTestProblem(t, test.terms)
+
+		run, err := p.prepare()
+		if test.fails {
+			if err == nil {
+				t.Errorf("%s: got no error", test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %s", test.name, err)
+			continue
+		}
+
+		if p.feasible != nil || p.covariance != nil {
+			t.Errorf("%s: problem was modified", test.name)
+		}
+		if run.feasible == nil {
+			t.Errorf("%s: missing feasible set", test.name)
+		}
+		if (run.covariance != nil) != test.terms.enabled() {
+			t.Errorf("%s: got covariance %v, want %v", test.name, run.covariance != nil, test.terms.enabled())
+		}
+	}
+}
+
+func TestRiskEval(t *testing.T) {
+	tests := []struct {
+		name  string    // Test Case
+		terms RiskTerms // Risk Terms
+	}{
+		{"no risk terms", RiskTerms{}},
+		{"volatility", RiskTerms{Volatility: 100.0}},
+		{"all risk terms", RiskTerms{Volatility: 100.0, Diversification: 1.0, Bets: 1.0}},
+	}
+
+	for _, test := range tests {
+		run, err := newTestProblem(t, test.terms).prepare()
+		if err != nil {
+			t.Errorf("%s: %s", test.name, err)
+			continue
+		}
+
+		n := len(run.Assets)
+		for i := 0; i < n; i++ {
+			allocation := make([]float32, n)
+			allocation[i] = 1.0
+			if value := riskEval(run, allocation); value < 0.0 {
+				t.Errorf("%s: got negative risk %.4f for %s", test.name, value, run.Assets[i].Ticker())
+			}
+		}
+	}
+}
+
+func TestMeanVarianceCovariance(t *testing.T) {
+	p := newTestProblem(t, RiskTerms{Volatility: 1.0})
+
+	if _, err := NewMeanVariance(MinVariance, 0.0, 0.0).Optimize(p); err != nil {
+		t.Fatal(err)
+	}
+	if p.feasible != nil || p.covariance != nil {
+		t.Errorf("problem was modified")
+	}
+}
+
+// Objective that prefers allocations with low volatility. It is flat when the
+// problem has no covariance matrix.
+type volatilityObjective struct{}
+
+// Evaluates an allocation.
+func (volatilityObjective) Eval(p *Problem, allocation []float32) float32 {
+	if p.covariance == nil {
+		return 0.0
+	}
+
+	return -float32(p.covariance.PortfolioVolatility(weights(allocation)))
+}
+
+func TestNSGA2RiskTerms(t *testing.T) {
+	tests := []struct {
+		name   string    // Test Case
+		terms  RiskTerms // Risk Terms
+		lowest bool      // Expected Pick of the Least Volatile Solution
+	}{
+		{"no risk terms", RiskTerms{}, false},
+		{"volatility", RiskTerms{Volatility: 1.0}, true},
+	}
+
+	for _, test := range tests {
+		p := newTestProblem(t, test.terms)
+		p.Objective = volatilityObjective{}
+
+		result, err := NewNSGA2(40, 20, 0.2).Optimize(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Least volatile solution of the front. Without a covariance matrix,
+		// all solutions tie and the first one is picked instead.
+		run, err := newTestProblem(t, RiskTerms{Volatility: 1.0}).prepare()
+		if err != nil {
+			t.Fatal(err)
+		}
+		run.Objective = volatilityObjective{}
+		lowest := result.Front.Solutions[0]
+		for _, s := range result.Front.Solutions[1:] {
+			if run.Objective.Eval(run, s.Weights) > run.Objective.Eval(run, lowest.Weights) {
+				lowest = s
+			}
+		}
+		if lowest == result.Front.Solutions[0] {
+			t.Fatalf("%s: first solution is already the least volatile", test.name)
+		}
+
+		if got := sameWeights(result.Weights, lowest.Weights); got != test.lowest {
+			t.Errorf("%s: got pick of the least volatile solution %v, want %v", test.name, got, test.lowest)
+		}
+	}
+}
+
+// Asserts whether two lists of weights are equal.
+func sameWeights(w1, w2 []float32) bool {
+	for i := range w1 {
+		if w1[i] != w2[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestCrossover(t *testing.T) {
+	p, err := newTestProblem(t, RiskTerms{}).prepare()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Each parent only holds the assets of one half.
+	n := len(p.Assets)
+	point := n / 2
+	g1 := &gene{dna: make([]float32, n)}
+	g2 := &gene{dna: make([]float32, n)}
+	for i := 0; i < point; i++ {
+		g1.dna[i] = 1.0 / float32(point)
+	}
+	for i := point; i < n; i++ {
+		g2.dna[i] = 1.0 / float32(n-point)
+	}
+
+	child := crossover(p, g1, g2)
+	for i := range child.dna {
+		parent := 1
+		if i >= point {
+			parent = 2
+		}
+		if child.dna[i] <= 0.0 {
+			t.Errorf("got no weight for %s, from parent %d", p.Assets[i].Ticker(), parent)
+		}
+	}
+}
+
+func TestSimplex(t *testing.T) {
+	tests := []struct {
+		name  string    // Test Case
+		lower []float64 // Lower Bounds
+		upper []float64 // Upper Bounds
+		fails bool      // Expected Failure
+	}{
+		{"feasible", []float64{0.0, 0.2, 0.0}, []float64{0.5, 0.5, 0.5}, false},
+		{"upper bounds below budget", []float64{0.0, 0.0, 0.0}, []float64{0.3, 0.3, 0.3}, true},
+		{"lower bounds above budget", []float64{0.5, 0.5, 0.5}, []float64{0.6, 0.6, 0.6}, true},
+	}
+
+	for _, test := range tests {
+		s := &simplex{lower: test.lower, upper: test.upper, budget: 1.0}
+
+		if err := s.validate(); (err != nil) != test.fails {
+			t.Errorf("%s: got error %v, want failure %v", test.name, err, test.fails)
+		}
+
+		// Projections terminate and respect bounds, even on empty simplices.
+		var sum float64
+		w := make([]float64, len(test.lower))
+		s.project([]float64{1.0, -1.0, 0.5}, w)
+		for i := range w {
+			if w[i] < test.lower[i] || w[i] > test.upper[i] {
+				t.Errorf("%s: got weight %.4f out of [%.2f, %.2f]", test.name, w[i], test.lower[i], test.upper[i])
+			}
+			sum += w[i]
+		}
+		if !test.fails && math.Abs(sum-1.0) > 1e-9 {
+			t.Errorf("%s: got weights summing to %.6f", test.name, sum)
+		}
+	}
+}
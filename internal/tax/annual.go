@@ -0,0 +1,264 @@
+/*
+ * MIT License
+ *
+ * Copyright(c) 2020 Pedro Henrique Penna <pedrohenriquepenna@gmail.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package tax
+
+import (
+	"fmt"
+	"os"
+	"portfolio/internal/database"
+	"portfolio/internal/ledger"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Annual Declaration Codes
+const (
+	AssetGroup = "07" // Bens e Direitos Group (Fundos)
+	AssetCode  = "03" // Bens e Direitos Code (Fundos Imobiliários)
+)
+
+// Holding of a Fund in an Annual Declaration
+type Holding struct {
+	AssetID      int     // Asset ID
+	CNPJ         string  // CNPJ of the Fund
+	PrevQuantity int     // Number of Shares at the End of the Previous Year
+	PrevCost     float64 // Total Acquisition Cost at the End of the Previous Year
+	Quantity     int     // Number of Shares at the End of the Year
+	Cost         float64 // Total Acquisition Cost at the End of the Year
+	Income       float64 // Exempt Income Received during the Year
+}
+
+// Annual Income Tax Declaration Report
+type AnnualReport struct {
+	Year     int        // Calendar Year
+	Holdings []*Holding // Funds Held or with Income in the Year (sorted by ticker)
+}
+
+// Returns the holding of an asset, creating it if needed.
+func holdingOf(holdings map[int]*Holding, assetID int) *Holding {
+	h, ok := holdings[assetID]
+	if !ok {
+		h = &Holding{AssetID: assetID}
+		h.CNPJ, _ = database.AssetCNPJ(assetID)
+		holdings[assetID] = h
+	}
+
+	return h
+}
+
+// Computes the annual income tax declaration report of a calendar year from a
+// list of transactions sorted by date. Positions are reported at acquisition
+// cost on December 31 of the year and of the previous year, and dividends
+// received during the year are reported as exempt income.
+func ComputeAnnual(transactions []*ledger.Transaction, year int) (*AnnualReport, error) {
+	start := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(1, 0, 0)
+
+	positions := ledger.NewPositions()
+	holdings := make(map[int]*Holding)
+
+	snapshot := func(previous bool) {
+		for assetID, p := range positions {
+			h := holdingOf(holdings, assetID)
+			if previous {
+				h.PrevQuantity = p.Quantity
+				h.PrevCost = round(p.Cost)
+			} else {
+				h.Quantity = p.Quantity
+				h.Cost = round(p.Cost)
+			}
+		}
+	}
+
+	next := 0
+
+	// Moves positions forward to a date, adjusting them for the splits and
+	// groupings in between, and applies transactions at their dates.
+	var date time.Time
+	advance := func(to time.Time) {
+		positions.Split(date, to)
+		date = to
+	}
+	apply := func(t *ledger.Transaction) error {
+		advance(t.Date)
+		_, err := positions.Apply(t)
+		return err
+	}
+
+	// Positions at the end of the previous year.
+	for ; next < len(transactions) && transactions[next].Date.Before(start); next++ {
+		if err := apply(transactions[next]); err != nil {
+			return nil, err
+		}
+	}
+	advance(start.AddDate(0, 0, -1))
+	snapshot(true)
+
+	// Positions and income during the year.
+	for ; next < len(transactions) && transactions[next].Date.Before(end); next++ {
+		t := transactions[next]
+		if err := apply(t); err != nil {
+			return nil, err
+		}
+		if t.Kind == ledger.Dividend {
+			holdingOf(holdings, t.AssetID).Income += t.Amount
+		}
+	}
+	advance(end.AddDate(0, 0, -1))
+	snapshot(false)
+
+	report := &AnnualReport{Year: year}
+	report.Holdings = make([]*Holding, 0, len(holdings))
+	for _, h := range holdings {
+		h.Income = round(h.Income)
+		report.Holdings = append(report.Holdings, h)
+	}
+	sort.Slice(report.Holdings, func(i, j int) bool {
+		ti, _ := database.AssetTicker(report.Holdings[i].AssetID)
+		tj, _ := database.AssetTicker(report.Holdings[j].AssetID)
+		return ti < tj
+	})
+
+	return report, nil
+}
+
+// Formats an amount in Brazilian notation (e.g. 1.234,56).
+func formatBRL(x float64) string {
+	sign := ""
+	if x < 0.0 {
+		sign = "-"
+		x = -x
+	}
+
+	s := fmt.Sprintf("%.2f", x)
+	integer, cents := s[:len(s)-3], s[len(s)-2:]
+
+	var b strings.Builder
+	for i, c := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(c)
+	}
+
+	return sign + b.String() + "," + cents
+}
+
+// Returns the description of a holding for the Bens e Direitos form.
+func (h *Holding) description(year int) string {
+	ticker, _ := database.AssetTicker(h.AssetID)
+
+	cnpj := h.CNPJ
+	if cnpj == "" {
+		cnpj = "(unknown)"
+	}
+
+	if h.Quantity == 0 {
+		return fmt.Sprintf("Cotas do FII %s, CNPJ %s, alienadas em %d.",
+			strings.ToUpper(ticker), cnpj, year)
+	}
+
+	return fmt.Sprintf("%d cotas do FII %s, CNPJ %s, ao custo total de R$ %s.",
+		h.Quantity, strings.ToUpper(ticker), cnpj, formatBRL(h.Cost))
+}
+
+/*============================================================================*
+ * Write()                                                                    *
+ *============================================================================*/
+
+// Writes an annual income tax declaration report into a file.
+func (r *AnnualReport) Write(file *os.File) error {
+	var total float64
+
+	// Invalid file.
+	if file == nil {
+		return fmt.Errorf("invalid report file")
+	}
+
+	fmt.Fprintf(file, "\nAnnual Income Tax Declaration %d\n", r.Year)
+
+	fmt.Fprintf(file, "\n  Bens e Direitos (Group %s, Code %s)\n", AssetGroup, AssetCode)
+	for _, h := range r.Holdings {
+		if h.PrevQuantity == 0 && h.Quantity == 0 {
+			continue
+		}
+		ticker, _ := database.AssetTicker(h.AssetID)
+		fmt.Fprintf(file, "\n    %s\n", ticker)
+		fmt.Fprintf(file, "      %-20s %s\n", "CNPJ", h.CNPJ)
+		fmt.Fprintf(file, "      %-20s %s\n", "Description", h.description(r.Year))
+		fmt.Fprintf(file, "      %-20s R$ %s\n", fmt.Sprintf("Position 31/12/%d", r.Year-1), formatBRL(h.PrevCost))
+		fmt.Fprintf(file, "      %-20s R$ %s\n", fmt.Sprintf("Position 31/12/%d", r.Year), formatBRL(h.Cost))
+	}
+
+	fmt.Fprintf(file, "\n  Rendimentos Isentos e Nao Tributaveis (FII Income)\n\n")
+	for _, h := range r.Holdings {
+		if h.Income == 0.0 {
+			continue
+		}
+		ticker, _ := database.AssetTicker(h.AssetID)
+		fmt.Fprintf(file, "    %-10s %-20s R$ %12s\n", ticker, h.CNPJ, formatBRL(h.Income))
+		total += h.Income
+	}
+	fmt.Fprintf(file, "    %-31s R$ %12s\n", "Total", formatBRL(total))
+	fmt.Fprintf(file, "\n")
+
+	return nil
+}
+
+/*============================================================================*
+ * Persist()                                                                  *
+ *============================================================================*/
+
+// Exports an annual income tax declaration report to a CSV file, one line per
+// fund.
+func (r *AnnualReport) Persist(filename string) error {
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	fmt.Fprintf(file, "ticker,cnpj,group,code,prev_quantity,prev_cost,quantity,cost,income,description\n")
+
+	for _, h := range r.Holdings {
+		ticker, _ := database.AssetTicker(h.AssetID)
+		fmt.Fprintf(file, "%s,%s,%s,%s,%d,%.2f,%d,%.2f,%.2f,\"%s\"\n",
+			ticker,
+			h.CNPJ,
+			AssetGroup,
+			AssetCode,
+			h.PrevQuantity,
+			h.PrevCost,
+			h.Quantity,
+			h.Cost,
+			h.Income,
+			h.description(r.Year),
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,136 @@
+/*
+ * MIT License
+ *
+ * Copyright(c) 2020 Pedro Henrique Penna <pedrohenriquepenna@gmail.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package tax
+
+import (
+	"fmt"
+	"os"
+	"portfolio/internal/database"
+	"sort"
+)
+
+// Returns the asset IDs of realized gains, in a stable order.
+func (m *Month) assetIDs() []int {
+	assetIDs := make([]int, 0, len(m.Gains))
+
+	for assetID := range m.Gains {
+		assetIDs = append(assetIDs, assetID)
+	}
+	sort.Ints(assetIDs)
+
+	return assetIDs
+}
+
+/*============================================================================*
+ * Write()                                                                    *
+ *============================================================================*/
+
+// Writes a tax report into a file.
+func (r *Report) Write(file *os.File) error {
+
+	// Invalid file.
+	if file == nil {
+		return fmt.Errorf("invalid report file")
+	}
+
+	fmt.Fprintf(file, "\nCapital Gains Tax on FII Sales (DARF %s)\n", DARFCode)
+
+	for _, m := range r.Months {
+		fmt.Fprintf(file, "\n  %s\n", m.Month.Format("2006-01"))
+		for _, assetID := range m.assetIDs() {
+			ticker, _ := database.AssetTicker(assetID)
+			fmt.Fprintf(file, "    %-20s R$ %12.2f\n", ticker, m.Gains[assetID])
+		}
+		fmt.Fprintf(file, "    %-20s R$ %12.2f\n", "Gross Sales", m.Sales)
+		fmt.Fprintf(file, "    %-20s R$ %12.2f\n", "Net Gain", m.NetGain)
+		fmt.Fprintf(file, "    %-20s R$ %12.2f\n", "Losses Offset", m.Offset)
+		fmt.Fprintf(file, "    %-20s R$ %12.2f\n", "Taxable Gain", m.Taxable)
+		fmt.Fprintf(file, "    %-20s R$ %12.2f\n", "Tax (20%)", m.Tax)
+		fmt.Fprintf(file, "    %-20s R$ %12.2f\n", "Withheld (IRRF)", m.Withheld)
+		fmt.Fprintf(file, "    %-20s R$ %12.2f\n", "Carried Tax", m.Carried)
+		if m.DARF > 0.0 {
+			fmt.Fprintf(file, "    %-20s R$ %12.2f due %s\n", "DARF",
+				m.DARF, m.DueDate.Format("2006-01-02"))
+		} else {
+			fmt.Fprintf(file, "    %-20s R$ %12.2f\n", "DARF", 0.0)
+		}
+		fmt.Fprintf(file, "    %-20s R$ %12.2f\n", "Losses to Carry", m.Losses)
+		fmt.Fprintf(file, "    %-20s R$ %12.2f\n", "Tax to Carry", m.Pending)
+	}
+	fmt.Fprintf(file, "\n")
+
+	return nil
+}
+
+/*============================================================================*
+ * Persist()                                                                  *
+ *============================================================================*/
+
+// Exports a tax report to a CSV file, one line per month.
+func (r *Report) Persist(filename string) error {
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	fmt.Fprintf(file, "month,sales,net_gain,losses_offset,taxable_gain,tax,"+
+		"withheld,carried_tax,darf,due_date,losses_to_carry,tax_to_carry,gains\n")
+
+	for _, m := range r.Months {
+		dueDate := ""
+		if m.DARF > 0.0 {
+			dueDate = m.DueDate.Format("2006-01-02")
+		}
+
+		gains := ""
+		for i, assetID := range m.assetIDs() {
+			ticker, _ := database.AssetTicker(assetID)
+			if i > 0 {
+				gains += ";"
+			}
+			gains += fmt.Sprintf("%s:%.2f", ticker, m.Gains[assetID])
+		}
+
+		fmt.Fprintf(file, "%s,%.2f,%.2f,%.2f,%.2f,%.2f,%.2f,%.2f,%.2f,%s,%.2f,%.2f,%s\n",
+			m.Month.Format("2006-01"),
+			m.Sales,
+			m.NetGain,
+			m.Offset,
+			m.Taxable,
+			m.Tax,
+			m.Withheld,
+			m.Carried,
+			m.DARF,
+			dueDate,
+			m.Losses,
+			m.Pending,
+			gains,
+		)
+	}
+
+	return nil
+}
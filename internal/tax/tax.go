@@ -0,0 +1,154 @@
+/*
+ * MIT License
+ *
+ * Copyright(c) 2020 Pedro Henrique Penna <pedrohenriquepenna@gmail.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package tax
+
+import (
+	"math"
+	"portfolio/internal/ledger"
+	"portfolio/internal/utils"
+	"time"
+)
+
+// Tax Rules for FII Sales by Individuals
+const (
+	Rate        = 0.20    // Tax Rate on Net Monthly Gains
+	WithheldTax = 0.00005 // Withholding Tax (IRRF) on Gross Sales
+	MinDARF     = 10.00   // Minimum DARF Amount
+	DARFCode    = "6015"  // DARF Revenue Code
+)
+
+// Month of Tax Assessment
+type Month struct {
+	Month    time.Time       // First Day of the Month
+	Sales    float64         // Gross Sales
+	Gains    map[int]float64 // Realized Gains per Asset (indexed by asset ID)
+	NetGain  float64         // Net Realized Gain
+	Offset   float64         // Carried Losses Offset against Gains
+	Taxable  float64         // Taxable Gain
+	Tax      float64         // Tax on Taxable Gain
+	Withheld float64         // Withholding Tax Credit Used
+	Carried  float64         // Tax Carried from Previous Months (below minimum DARF)
+	DARF     float64         // DARF Amount Due
+	DueDate  time.Time       // DARF Due Date
+	Losses   float64         // Losses to Carry Forward (after this month)
+	Pending  float64         // Tax to Carry Forward (below minimum DARF)
+}
+
+// Tax Report
+type Report struct {
+	Months []*Month // Months with Sales, Gains or Tax Due
+}
+
+// Returns the first day of the month of a date.
+func monthStart(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// Rounds an amount to cents.
+func round(x float64) float64 {
+	return math.Round(x*100) / 100
+}
+
+// Computes monthly capital gains tax on FII sales from a list of transactions
+// sorted by date. Gains are realized by sales and by returns of capital in
+// excess of the acquisition cost, as booked by the ledger, and positions are
+// adjusted for splits and groupings. Net losses are
+// carried forward, withheld tax is deducted from the tax due, and amounts below
+// the minimum DARF are carried to the following months.
+func Compute(transactions []*ledger.Transaction) (*Report, error) {
+	var losses, pending, credit float64
+
+	report := &Report{}
+	report.Months = make([]*Month, 0)
+
+	if len(transactions) == 0 {
+		return report, nil
+	}
+
+	positions := ledger.NewPositions()
+
+	next := 0
+	first := monthStart(transactions[0].Date)
+	last := monthStart(transactions[len(transactions)-1].Date)
+	for month := first; !month.After(last); month = month.AddDate(0, 1, 0) {
+		m := &Month{}
+		m.Month = month
+		m.Gains = make(map[int]float64)
+
+		// Apply transactions of this month.
+		for ; next < len(transactions) && monthStart(transactions[next].Date).Equal(month); next++ {
+			t := transactions[next]
+
+			if next > 0 {
+				positions.Split(transactions[next-1].Date, t.Date)
+			}
+			gain, err := positions.Apply(t)
+			if err != nil {
+				return nil, err
+			}
+
+			if t.Kind == ledger.Sell {
+				m.Sales += t.Value()
+			}
+			if gain != 0.0 {
+				m.Gains[t.AssetID] += gain
+				m.NetGain += gain
+			}
+		}
+
+		// Offset carried losses.
+		if m.NetGain < 0.0 {
+			losses += -m.NetGain
+		} else {
+			m.Offset = math.Min(losses, m.NetGain)
+			losses -= m.Offset
+			m.Taxable = m.NetGain - m.Offset
+		}
+		m.Tax = round(Rate * m.Taxable)
+
+		// Deduct withheld tax.
+		credit += round(WithheldTax * m.Sales)
+		m.Withheld = math.Min(credit, m.Tax)
+		credit -= m.Withheld
+
+		// Issue DARF.
+		m.Carried = pending
+		pending += m.Tax - m.Withheld
+		if pending >= MinDARF {
+			m.DARF = round(pending)
+			m.DueDate = utils.LastBusinessDay(month.Year(), month.Month()+1)
+			pending = 0.0
+		}
+
+		m.Losses = round(losses)
+		m.Pending = round(pending)
+
+		if m.Sales > 0.0 || m.NetGain != 0.0 || m.DARF > 0.0 {
+			report.Months = append(report.Months, m)
+		}
+	}
+
+	return report, nil
+}
@@ -0,0 +1,109 @@
+/*
+ * MIT License
+ *
+ * Copyright(c) 2020 Pedro Henrique Penna <pedrohenriquepenna@gmail.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package tax
+
+import (
+	"portfolio/internal/ledger"
+	"testing"
+	"time"
+)
+
+// Returns a date.
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// Returns a trade of shares of asset 0.
+func trade(d time.Time, kind, quantity int, price float64) *ledger.Transaction {
+	return &ledger.Transaction{Date: d, Kind: kind, Quantity: quantity, Price: price}
+}
+
+func TestDueDate(t *testing.T) {
+	tests := []struct {
+		sale time.Time // Date of Sale
+		due  time.Time // Expected DARF Due Date
+	}{
+		{date(2023, time.October, 10), date(2023, time.November, 30)},
+		{date(2024, time.August, 5), date(2024, time.September, 30)},
+		{date(2024, time.February, 15), date(2024, time.March, 28)}, // Good Friday and weekend
+		{date(2024, time.May, 2), date(2024, time.June, 28)},        // Weekend
+	}
+
+	for _, test := range tests {
+		transactions := []*ledger.Transaction{
+			trade(test.sale, ledger.Buy, 100, 100.0),
+			trade(test.sale, ledger.Sell, 100, 110.0),
+		}
+
+		report, err := Compute(transactions)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(report.Months) != 1 {
+			t.Fatalf("%s: got %d months, want 1", test.sale.Format("2006-01-02"), len(report.Months))
+		}
+		if m := report.Months[0]; !m.DueDate.Equal(test.due) {
+			t.Errorf("%s: got due date %s, want %s", test.sale.Format("2006-01-02"),
+				m.DueDate.Format("2006-01-02"), test.due.Format("2006-01-02"))
+		}
+	}
+}
+
+func TestCarryForward(t *testing.T) {
+	transactions := []*ledger.Transaction{
+		trade(date(2023, time.January, 10), ledger.Buy, 30, 100.0),
+		trade(date(2023, time.January, 20), ledger.Sell, 10, 102.5), // Gain 25.00
+		trade(date(2023, time.February, 20), ledger.Sell, 10, 90.0), // Loss 100.00
+		trade(date(2023, time.March, 20), ledger.Sell, 10, 113.0),   // Gain 130.00
+	}
+
+	tests := []struct {
+		taxable float64 // Taxable Gain
+		darf    float64 // DARF Amount Due
+		losses  float64 // Losses to Carry Forward
+		pending float64 // Tax to Carry Forward
+	}{
+		{25.00, 0.00, 0.00, 4.95},  // 5.00 - 0.05 withheld, below the minimum DARF
+		{0.00, 0.00, 100.00, 4.95}, // Loss carried forward
+		{30.00, 10.84, 0.00, 0.00}, // 6.00 - 0.11 withheld (0.05 from February) + 4.95 carried
+	}
+
+	report, err := Compute(transactions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Months) != len(tests) {
+		t.Fatalf("got %d months, want %d", len(report.Months), len(tests))
+	}
+
+	for i, test := range tests {
+		m := report.Months[i]
+		if m.Taxable != test.taxable || m.DARF != test.darf || m.Losses != test.losses || m.Pending != test.pending {
+			t.Errorf("%s: got taxable %.2f, DARF %.2f, losses %.2f, pending %.2f; want %.2f, %.2f, %.2f, %.2f",
+				m.Month.Format("2006-01"), m.Taxable, m.DARF, m.Losses, m.Pending,
+				test.taxable, test.darf, test.losses, test.pending)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+/*
+ * MIT License
+ *
+ * Copyright(c) 2020 Pedro Henrique Penna <pedrohenriquepenna@gmail.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package trade
+
+import (
+	"fmt"
+	"portfolio/internal/wallet"
+)
+
+// Computes the buy orders (in whole shares) that invest a new contribution
+// into the current wallet, moving it as close as possible to the allocation of
+// a target wallet without selling anything. Cash already in the current
+// wallet is invested as well. Each new order is at least the minimum trade
+// size. It returns the orders along with the leftover cash.
+func Contribute(current, target *wallet.Wallet, amount, minTrade float32) ([]*Order, float32, error) {
+
+	if amount < 0.0 {
+		return nil, 0.0, fmt.Errorf("invalid contribution")
+	}
+
+	cash := current.Cash() + amount
+	total := current.PositionsValue() + cash
+
+	positions, err := newPositions(current, target, total)
+	if err != nil {
+		return nil, 0.0, err
+	}
+
+	cash = spend(positions, cash, minTrade)
+
+	// Build orders.
+	orders := make([]*Order, 0)
+	for _, p := range positions {
+		if p.delta > 0 {
+			orders = append(orders, &Order{p.assetID, Buy, p.delta, p.price})
+		}
+	}
+	sortOrders(orders)
+
+	return orders, cash, nil
+}
@@ -0,0 +1,75 @@
+/*
+ * MIT License
+ *
+ * Copyright(c) 2020 Pedro Henrique Penna <pedrohenriquepenna@gmail.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package trade
+
+import (
+	"math"
+	"testing"
+)
+
+func TestContributeCash(t *testing.T) {
+	target := map[string]float32{"hglg11": 0.4, "kncr11": 0.3, "xpml11": 0.3}
+
+	tests := []struct {
+		name     string         // Test Name
+		holdings map[string]int // Current Holdings
+		cash     float32        // Current Cash
+		amount   float32        // Contribution
+		minTrade float32        // Minimum Trade Size
+	}{
+		{"no contribution", map[string]int{"hglg11": 10}, 0.0, 0.0, 0.0},
+		{"holdings", map[string]int{"hglg11": 20, "hgre11": 30}, 0.0, 5000.0, 0.0},
+		{"holdings and cash", map[string]int{"kncr11": 50}, 1200.0, 3000.0, 500.0},
+		{"cash only", nil, 0.0, 10000.0, 1000.0},
+	}
+
+	for _, test := range tests {
+		current := newWallet(t, test.holdings, test.cash, nil)
+		recommended := newWallet(t, nil, 0.0, target)
+
+		orders, leftover, err := Contribute(current, recommended, test.amount, test.minTrade)
+		if err != nil {
+			t.Fatalf("%s: %s", test.name, err.Error())
+		}
+
+		for _, o := range orders {
+			if o.Side != Buy {
+				t.Errorf("%s: contribution sells asset %d", test.name, o.AssetID)
+			}
+			if o.Amount() < test.minTrade {
+				t.Errorf("%s: order of %.2f below the minimum trade size", test.name, o.Amount())
+			}
+		}
+
+		if leftover < 0.0 {
+			t.Errorf("%s: negative leftover cash %.2f", test.name, leftover)
+		}
+
+		want := float64(test.cash+test.amount) + cashFlow(t, current, orders)
+		if math.Abs(float64(leftover)-want) > 0.01 {
+			t.Errorf("%s: got leftover cash %.2f, want %.2f", test.name, leftover, want)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+/*
+ * MIT License
+ *
+ * Copyright(c) 2020 Pedro Henrique Penna <pedrohenriquepenna@gmail.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package trade
+
+import (
+	"fmt"
+	"os"
+	"portfolio/internal/database"
+	"sort"
+)
+
+// Order Sides
+const (
+	Buy = iota
+	Sell
+)
+
+var sidesDB = map[int]string{
+	Buy:  "BUY",
+	Sell: "SELL",
+}
+
+// Order
+type Order struct {
+	AssetID  int     // Asset ID
+	Side     int     // Side
+	Quantity int     // Number of Shares
+	Price    float32 // Share Price
+}
+
+// Returns the amount of an order.
+func (o *Order) Amount() float32 {
+	return float32(o.Quantity) * o.Price
+}
+
+// Returns the latest share price of an asset.
+func lastSharePrice(assetID int) (float32, error) {
+	a, err := database.GetAssetByID(assetID)
+	if err != nil {
+		return 0.0, err
+	}
+
+	price := a.LastSharePrice()
+	if price <= 0.0 {
+		return 0.0, fmt.Errorf("no share price for " + a.Ticker())
+	}
+
+	return price, nil
+}
+
+// Sorts orders: sells come first, then larger amounts.
+func sortOrders(orders []*Order) {
+	sort.Slice(orders, func(i, j int) bool {
+		if orders[i].Side != orders[j].Side {
+			return orders[i].Side == Sell
+		}
+		return orders[i].Amount() > orders[j].Amount()
+	})
+}
+
+/*============================================================================*
+ * WriteOrders()                                                              *
+ *============================================================================*/
+
+// Writes a list of orders into a file, along with the leftover cash.
+func WriteOrders(file *os.File, orders []*Order, cash float32) error {
+	var bought, sold float32
+
+	// Invalid file.
+	if file == nil {
+		return fmt.Errorf("invalid orders file")
+	}
+
+	fmt.Fprintf(file, "\nOrders\n")
+	for _, o := range orders {
+		ticker, _ := database.AssetTicker(o.AssetID)
+		fmt.Fprintf(file,
+			"  %-4s %-6s %6d x %8.2f = R$ %10.2f\n",
+			sidesDB[o.Side],
+			ticker,
+			o.Quantity,
+			o.Price,
+			o.Amount(),
+		)
+
+		if o.Side == Buy {
+			bought += o.Amount()
+		} else {
+			sold += o.Amount()
+		}
+	}
+
+	fmt.Fprintf(file, "\n  %-15s R$ %10.2f\n", "Sold", sold)
+	fmt.Fprintf(file, "  %-15s R$ %10.2f\n", "Bought", bought)
+	fmt.Fprintf(file, "  %-15s R$ %10.2f\n\n", "Leftover Cash", cash)
+
+	return nil
+}
@@ -0,0 +1,196 @@
+/*
+ * MIT License
+ *
+ * Copyright(c) 2020 Pedro Henrique Penna <pedrohenriquepenna@gmail.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package trade
+
+import (
+	"fmt"
+	"math"
+	"portfolio/internal/wallet"
+)
+
+// Position under Rebalance
+type position struct {
+	assetID  int     // Asset ID
+	price    float32 // Share Price
+	quantity int     // Current Number of Shares
+	delta    int     // Number of Shares to Trade
+	target   float32 // Target Value
+}
+
+// Returns the value of a position after trading.
+func (p *position) value() float32 {
+	return float32(p.quantity+p.delta) * p.price
+}
+
+// Returns the number of shares to buy next, so that new buy orders are not
+// smaller than the minimum trade size.
+func (p *position) step(minTrade float32) int {
+	if p.delta > 0 {
+		return 1
+	}
+
+	return int(math.Max(1, math.Ceil(float64(minTrade/p.price))))
+}
+
+// Builds the positions involved in a rebalance.
+func newPositions(current, target *wallet.Wallet, total float32) ([]*position, error) {
+	positions := make([]*position, 0)
+	seen := make(map[int]bool)
+
+	add := func(assetID int) error {
+		if seen[assetID] {
+			return nil
+		}
+		seen[assetID] = true
+
+		price, err := lastSharePrice(assetID)
+		if err != nil {
+			return err
+		}
+
+		p := &position{}
+		p.assetID = assetID
+		p.price = price
+		p.target = target.Allocation()[assetID] * total
+		if h, ok := current.Holding(assetID); ok {
+			p.quantity = h.Quantity
+		}
+		positions = append(positions, p)
+
+		return nil
+	}
+
+	for assetID := range current.Holdings() {
+		if err := add(assetID); err != nil {
+			return nil, err
+		}
+	}
+	for assetID, weight := range target.Allocation() {
+		if weight > 0.0 {
+			if err := add(assetID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return positions, nil
+}
+
+// Spends the available cash on whole shares, favoring the positions that are
+// furthest below their target value. Positions being sold are not bought.
+func spend(positions []*position, cash, minTrade float32) float32 {
+	for {
+		var best *position
+
+		for _, p := range positions {
+			if p.delta < 0 || p.target <= 0.0 {
+				continue
+			}
+			if float32(p.step(minTrade))*p.price > cash {
+				continue
+			}
+			if best == nil || p.target-p.value() > best.target-best.value() {
+				best = p
+			}
+		}
+
+		// Nothing affordable.
+		if best == nil {
+			return cash
+		}
+
+		step := best.step(minTrade)
+		best.delta += step
+		cash -= float32(step) * best.price
+	}
+}
+
+// Computes the orders (in whole shares) that rebalance the current wallet
+// toward the allocation of a target wallet, given the latest share prices.
+// Orders smaller than the minimum trade size are skipped, except for closing
+// positions, and the leftover cash is kept as small as possible. It returns
+// the orders along with the leftover cash.
+func Rebalance(current, target *wallet.Wallet, minTrade float32) ([]*Order, float32, error) {
+
+	if !current.HasHoldings() && current.Cash() <= 0.0 {
+		return nil, 0.0, fmt.Errorf("current wallet has no holdings")
+	}
+
+	total := current.MarketValue()
+	positions, err := newPositions(current, target, total)
+	if err != nil {
+		return nil, 0.0, err
+	}
+
+	// Move to target, rounding down to whole shares.
+	cash := current.Cash()
+	for _, p := range positions {
+		p.delta = int(p.target/p.price) - p.quantity
+
+		// Too small.
+		if p.target > 0.0 && float32(math.Abs(float64(p.delta)))*p.price < minTrade {
+			p.delta = 0
+		}
+
+		cash -= float32(p.delta) * p.price
+	}
+
+	// Skipped sells may leave us short of cash: buy less.
+	for cash < 0.0 {
+		var worst *position
+
+		for _, p := range positions {
+			if p.delta > 0 && (worst == nil || p.value()-p.target > worst.value()-worst.target) {
+				worst = p
+			}
+		}
+
+		if worst == nil {
+			return nil, 0.0, fmt.Errorf("not enough cash to rebalance")
+		}
+
+		worst.delta--
+		cash += worst.price
+		if worst.delta > 0 && float32(worst.delta)*worst.price < minTrade {
+			cash += float32(worst.delta) * worst.price
+			worst.delta = 0
+		}
+	}
+
+	cash = spend(positions, cash, minTrade)
+
+	// Build orders.
+	orders := make([]*Order, 0)
+	for _, p := range positions {
+		if p.delta > 0 {
+			orders = append(orders, &Order{p.assetID, Buy, p.delta, p.price})
+		} else if p.delta < 0 {
+			orders = append(orders, &Order{p.assetID, Sell, -p.delta, p.price})
+		}
+	}
+	sortOrders(orders)
+
+	return orders, cash, nil
+}
@@ -0,0 +1,130 @@
+/*
+ * MIT License
+ *
+ * Copyright(c) 2020 Pedro Henrique Penna <pedrohenriquepenna@gmail.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package trade
+
+import (
+	"math"
+	"os"
+	"portfolio/internal/database"
+	"portfolio/internal/wallet"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+
+	// Paths are relative to the root of the repository.
+	if err := os.Chdir("../.."); err != nil {
+		panic(err.Error())
+	}
+	if err := database.Load("default.registry", "default.taxonomy"); err != nil {
+		panic(err.Error())
+	}
+
+	os.Exit(m.Run())
+}
+
+// Creates a wallet with some holdings (indexed by ticker), cash and
+// allocation (indexed by ticker).
+func newWallet(t *testing.T, holdings map[string]int, cash float32, allocation map[string]float32) *wallet.Wallet {
+	w := wallet.New("test")
+
+	for ticker, quantity := range holdings {
+		assetID, err := database.GetAssetID(ticker)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.SetHolding(assetID, quantity, 100.0)
+	}
+	w.SetCash(cash)
+
+	if allocation != nil {
+		weights := make(map[int]float32)
+		for ticker, weight := range allocation {
+			assetID, err := database.GetAssetID(ticker)
+			if err != nil {
+				t.Fatal(err)
+			}
+			weights[assetID] = weight
+		}
+		w.SetAllocation(weights)
+	}
+
+	return w
+}
+
+// Returns the cash flow of some orders (sells minus buys), checking that no
+// order sells more shares than held.
+func cashFlow(t *testing.T, current *wallet.Wallet, orders []*Order) float64 {
+	var flow float64
+
+	for _, o := range orders {
+		switch o.Side {
+		case Buy:
+			flow -= float64(o.Amount())
+		case Sell:
+			flow += float64(o.Amount())
+			if h, ok := current.Holding(o.AssetID); !ok || o.Quantity > h.Quantity {
+				t.Errorf("selling %d shares of asset %d, more than held", o.Quantity, o.AssetID)
+			}
+		}
+	}
+
+	return flow
+}
+
+func TestRebalanceCash(t *testing.T) {
+	target := map[string]float32{"hglg11": 0.4, "kncr11": 0.3, "xpml11": 0.3}
+
+	tests := []struct {
+		name     string         // Test Name
+		holdings map[string]int // Current Holdings
+		cash     float32        // Current Cash
+		minTrade float32        // Minimum Trade Size
+	}{
+		{"holdings", map[string]int{"hglg11": 20, "hgre11": 30, "visc11": 40}, 0.0, 0.0},
+		{"holdings and cash", map[string]int{"hglg11": 5, "knip11": 50}, 2500.0, 100.0},
+		{"cash only", nil, 10000.0, 0.0},
+		{"large minimum trade", map[string]int{"hglg11": 20, "kncr11": 10}, 300.0, 1000.0},
+	}
+
+	for _, test := range tests {
+		current := newWallet(t, test.holdings, test.cash, nil)
+		recommended := newWallet(t, nil, 0.0, target)
+
+		orders, leftover, err := Rebalance(current, recommended, test.minTrade)
+		if err != nil {
+			t.Fatalf("%s: %s", test.name, err.Error())
+		}
+
+		if leftover < 0.0 {
+			t.Errorf("%s: negative leftover cash %.2f", test.name, leftover)
+		}
+
+		want := float64(test.cash) + cashFlow(t, current, orders)
+		if math.Abs(float64(leftover)-want) > 0.01 {
+			t.Errorf("%s: got leftover cash %.2f, want %.2f", test.name, leftover, want)
+		}
+	}
+}
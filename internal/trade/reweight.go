@@ -0,0 +1,118 @@
+/*
+ * MIT License
+ *
+ * Copyright(c) 2020 Pedro Henrique Penna <pedrohenriquepenna@gmail.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package trade
+
+import (
+	"fmt"
+	"os"
+	"portfolio/internal/database"
+	"portfolio/internal/wallet"
+	"sort"
+)
+
+// Smallest Change of Weight Worth Reporting
+const minWeightChange = 1e-4
+
+// Change of the Weight of an Asset
+type WeightChange struct {
+	AssetID int     // Asset ID
+	Current float32 // Current Weight
+	Target  float32 // Target Weight
+}
+
+// Returns the weight to buy (if positive) or to sell (if negative).
+func (c *WeightChange) Delta() float32 {
+	return c.Target - c.Current
+}
+
+// Computes the changes of weights that move the allocation of the current
+// wallet to that of a target wallet. Unlike Rebalance(), it needs no holdings,
+// so it works on wallets given only as percentages. Changes are sorted as
+// orders: sells come first, then larger changes.
+func Reweight(current, target *wallet.Wallet) []*WeightChange {
+	changes := make([]*WeightChange, 0)
+	seen := make(map[int]bool)
+
+	add := func(assetID int) {
+		if seen[assetID] {
+			return
+		}
+		seen[assetID] = true
+
+		c := &WeightChange{assetID, current.Allocation()[assetID], target.Allocation()[assetID]}
+		if c.Delta() > minWeightChange || c.Delta() < -minWeightChange {
+			changes = append(changes, c)
+		}
+	}
+
+	for assetID := range current.Allocation() {
+		add(assetID)
+	}
+	for assetID := range target.Allocation() {
+		add(assetID)
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if (changes[i].Delta() < 0.0) != (changes[j].Delta() < 0.0) {
+			return changes[i].Delta() < 0.0
+		}
+		if changes[i].Delta() < 0.0 {
+			return changes[i].Delta() < changes[j].Delta()
+		}
+		return changes[i].Delta() > changes[j].Delta()
+	})
+
+	return changes
+}
+
+// Writes a list of weight changes into a file.
+func WriteWeightChanges(file *os.File, changes []*WeightChange) error {
+
+	// Invalid file.
+	if file == nil {
+		return fmt.Errorf("invalid orders file")
+	}
+
+	fmt.Fprintf(file, "\nWeight Changes\n")
+	for _, c := range changes {
+		side := Buy
+		if c.Delta() < 0.0 {
+			side = Sell
+		}
+
+		ticker, _ := database.AssetTicker(c.AssetID)
+		fmt.Fprintf(file,
+			"  %-4s %-6s %6.2f %% -> %6.2f %% (%+6.2f %%)\n",
+			sidesDB[side],
+			ticker,
+			100*c.Current,
+			100*c.Target,
+			100*c.Delta(),
+		)
+	}
+	fmt.Fprintf(file, "\n")
+
+	return nil
+}
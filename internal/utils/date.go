@@ -89,3 +89,88 @@ func MonthDiff(a, b time.Time) int {
 
 	return months
 }
+
+// Computes the fractional number of years between two dates.
+func YearFrac(a, b time.Time) float32 {
+	return float32(b.Sub(a).Hours() / 24.0 / 365.25)
+}
+
+// Computes the date of Easter Sunday in a given year (Anonymous Gregorian
+// algorithm).
+func Easter(year int) time.Time {
+	a := year % 19
+	b := year / 100
+	c := year % 100
+	d := b / 4
+	e := b % 4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i := c / 4
+	k := c % 4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
+
+// Asserts if a date is a bank holiday in Brazil (national holidays, plus
+// Carnival, Good Friday and Corpus Christi).
+func IsHoliday(date time.Time) bool {
+	holidays := []struct {
+		month time.Month
+		day   int
+	}{
+		{time.January, 1},
+		{time.April, 21},
+		{time.May, 1},
+		{time.September, 7},
+		{time.October, 12},
+		{time.November, 2},
+		{time.November, 15},
+		{time.December, 25},
+	}
+
+	for _, h := range holidays {
+		if date.Month() == h.month && date.Day() == h.day {
+			return true
+		}
+	}
+
+	// Consciência Negra (Lei 14.759/2023).
+	if date.Year() >= 2024 && date.Month() == time.November && date.Day() == 20 {
+		return true
+	}
+
+	easter := Easter(date.Year())
+	for _, offset := range []int{-48, -47, -2, 60} {
+		holiday := easter.AddDate(0, 0, offset)
+		if date.Month() == holiday.Month() && date.Day() == holiday.Day() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Asserts if a date is a business day in Brazil.
+func IsBusinessDay(date time.Time) bool {
+	if date.Weekday() == time.Saturday || date.Weekday() == time.Sunday {
+		return false
+	}
+
+	return !IsHoliday(date)
+}
+
+// Returns the last business day of a month.
+func LastBusinessDay(year int, month time.Month) time.Time {
+	date := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC)
+
+	for !IsBusinessDay(date) {
+		date = date.AddDate(0, 0, -1)
+	}
+
+	return date
+}
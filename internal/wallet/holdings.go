@@ -0,0 +1,196 @@
+/*
+ * MIT License
+ *
+ * Copyright(c) 2020 Pedro Henrique Penna <pedrohenriquepenna@gmail.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package wallet
+
+import (
+	"portfolio/internal/database"
+	"time"
+)
+
+// Holding
+type Holding struct {
+	Quantity     int     // Number of Shares
+	AveragePrice float32 // Average Purchase Price
+}
+
+// Returns the cost basis of a holding.
+func (h *Holding) Cost() float32 {
+	return float32(h.Quantity) * h.AveragePrice
+}
+
+// Returns the market value of a holding, given the share price.
+func (h *Holding) Value(price float32) float32 {
+	return float32(h.Quantity) * price
+}
+
+// Returns the share price of a holding at the valuation date of the target
+// wallet, as quoted at that time. The latest share price is used for dates
+// past the history of the asset, and the average purchase price for months
+// without a price.
+func (wallet *Wallet) sharePrice(assetID int, h *Holding) float32 {
+	a, err := database.GetAssetByID(assetID)
+	if err != nil {
+		return 0.0
+	}
+
+	if wallet.date.IsZero() || !wallet.date.Before(a.EndDate()) {
+		return a.LastSharePrice()
+	}
+	if price, ok := a.RawSharePriceAt(wallet.date); ok {
+		return price
+	}
+
+	return h.AveragePrice
+}
+
+/*============================================================================*
+ * Holdings                                                                   *
+ *============================================================================*/
+
+// Asserts if the target wallet tracks the number of shares it holds.
+func (wallet *Wallet) HasHoldings() bool {
+	return len(wallet.holdings) > 0
+}
+
+// Sets the holding of an asset in the target wallet. A non-positive quantity
+// removes the holding.
+func (wallet *Wallet) SetHolding(assetID, quantity int, averagePrice float32) {
+
+	if quantity <= 0 {
+		delete(wallet.holdings, assetID)
+	} else {
+		wallet.holdings[assetID] = &Holding{quantity, averagePrice}
+	}
+
+	wallet.updateAllocation()
+}
+
+// Returns the holding of an asset in the target wallet.
+func (wallet *Wallet) Holding(assetID int) (*Holding, bool) {
+	h, ok := wallet.holdings[assetID]
+	return h, ok
+}
+
+// Returns the holdings of the target wallet (indexed by asset ID).
+func (wallet *Wallet) Holdings() map[int]*Holding {
+	return wallet.holdings
+}
+
+// Sets the valuation date of the target wallet, at which its holdings are
+// priced. The zero date means the latest share prices.
+func (wallet *Wallet) SetValuationDate(date time.Time) {
+	wallet.date = date
+	wallet.updateAllocation()
+}
+
+// Sets the cash balance of the target wallet.
+func (wallet *Wallet) SetCash(cash float32) {
+	wallet.cash = cash
+}
+
+// Returns the cash balance of the target wallet.
+func (wallet *Wallet) Cash() float32 {
+	return wallet.cash
+}
+
+// Sets the realized gain of the target wallet.
+func (wallet *Wallet) SetRealizedGain(realized float32) {
+	wallet.realized = realized
+}
+
+// Returns the realized gain of the target wallet.
+func (wallet *Wallet) RealizedGain() float32 {
+	return wallet.realized
+}
+
+// Sets the dividend income received by the target wallet.
+func (wallet *Wallet) SetIncome(income float32) {
+	wallet.income = income
+}
+
+// Returns the dividend income received by the target wallet.
+func (wallet *Wallet) Income() float32 {
+	return wallet.income
+}
+
+// Recomputes the allocation of the target wallet from the market value of its
+// holdings.
+func (wallet *Wallet) updateAllocation() {
+	var total float32
+
+	if !wallet.HasHoldings() {
+		return
+	}
+
+	values := make(map[int]float32)
+	for assetID, h := range wallet.holdings {
+		values[assetID] = h.Value(wallet.sharePrice(assetID, h))
+		total += values[assetID]
+	}
+
+	wallet.allocation = make(map[int]float32)
+	if total > 0.0 {
+		for assetID, value := range values {
+			wallet.allocation[assetID] = value / total
+		}
+	}
+}
+
+/*============================================================================*
+ * Valuation                                                                  *
+ *============================================================================*/
+
+// Returns the market value of the positions of the target wallet, using the
+// share prices at its valuation date.
+func (wallet *Wallet) PositionsValue() float32 {
+	var value float32
+
+	for assetID, h := range wallet.holdings {
+		value += h.Value(wallet.sharePrice(assetID, h))
+	}
+
+	return value
+}
+
+// Returns the market value of the target wallet, including cash.
+func (wallet *Wallet) MarketValue() float32 {
+	return wallet.PositionsValue() + wallet.cash
+}
+
+// Returns the cost basis of the positions of the target wallet.
+func (wallet *Wallet) CostBasis() float32 {
+	var cost float32
+
+	for _, h := range wallet.holdings {
+		cost += h.Cost()
+	}
+
+	return cost
+}
+
+// Returns the unrealized gain of the positions of the target wallet.
+func (wallet *Wallet) UnrealizedGain() float32 {
+	return wallet.PositionsValue() - wallet.CostBasis()
+}
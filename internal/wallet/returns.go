@@ -0,0 +1,192 @@
+/*
+ * MIT License
+ *
+ * Copyright(c) 2020 Pedro Henrique Penna <pedrohenriquepenna@gmail.com>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package wallet
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"portfolio/internal/asset"
+	"portfolio/internal/covariance"
+	"portfolio/internal/database"
+	"portfolio/internal/utils"
+	"sort"
+	"time"
+)
+
+// Returns the last day of the month of a date.
+func monthEnd(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month()+1, 0, 0, 0, 0, 0, time.UTC)
+}
+
+// Computes the total return index of the allocation of the target wallet,
+// along with its dates. The index starts at one in the first month of history
+// of any of its assets, and the allocation is rebalanced monthly, with weights
+// renormalized among the assets with history in each month, so that assets
+// enter and leave the index as their histories start and end. Dividends are
+// reinvested, and months in which no asset has history carry the index over.
+func (wallet *Wallet) TotalReturnIndex() ([]time.Time, []float32, error) {
+	var start, end time.Time
+
+	assets := make([]*asset.Asset, 0, len(wallet.allocation))
+	weights := make([]float32, 0, len(wallet.allocation))
+
+	// Whole history of held assets.
+	for assetID, weight := range wallet.allocation {
+		if weight <= 0.0 {
+			continue
+		}
+
+		a, err := database.GetAssetByID(assetID)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if start.IsZero() || a.StartDate().Before(start) {
+			start = a.StartDate()
+		}
+		if end.IsZero() || a.EndDate().After(end) {
+			end = a.EndDate()
+		}
+		assets = append(assets, a)
+		weights = append(weights, weight)
+	}
+
+	if len(assets) == 0 {
+		return nil, nil, fmt.Errorf("empty wallet")
+	}
+
+	dates := []time.Time{monthEnd(start)}
+	index := []float32{1.0}
+
+	for date := monthEnd(dates[0].AddDate(0, 0, 1)); !date.After(monthEnd(end)); date = monthEnd(date.AddDate(0, 0, 1)) {
+		var growth, total float32
+
+		prev := dates[len(dates)-1]
+		for i, a := range assets {
+			first, ok1 := a.TotalReturnIndexAt(prev)
+			curr, ok2 := a.TotalReturnIndexAt(date)
+			if !ok1 || !ok2 || first <= 0.0 {
+				continue
+			}
+
+			growth += weights[i] * curr / first
+			total += weights[i]
+		}
+
+		value := index[len(index)-1]
+		if total > 0.0 {
+			value *= growth / total
+		}
+
+		dates = append(dates, date)
+		index = append(index, value)
+	}
+
+	return dates, index, nil
+}
+
+// Returns the annualized return of a total return index.
+func annualize(dates []time.Time, index []float32) float32 {
+
+	years := utils.YearFrac(dates[0], dates[len(dates)-1])
+	if years <= 0.0 {
+		return 0.0
+	}
+
+	return float32(math.Pow(float64(index[len(index)-1]), 1.0/float64(years))) - 1.0
+}
+
+// Computes the annualized total return of the allocation of the target wallet
+// over the period covered by its total return index.
+func (wallet *Wallet) AnnualizedTotalReturn() (float32, error) {
+
+	dates, index, err := wallet.TotalReturnIndex()
+	if err != nil {
+		return 0.0, err
+	}
+
+	return annualize(dates, index), nil
+}
+
+// Estimates the covariance matrix of the assets held by the target wallet,
+// along with their weights in the allocation, sorted by asset ID.
+func (wallet *Wallet) Covariance() (*covariance.Matrix, []float64, error) {
+	var total float32
+
+	assetIDs := make([]int, 0, len(wallet.allocation))
+	for assetID, weight := range wallet.allocation {
+		if weight > 0.0 {
+			assetIDs = append(assetIDs, assetID)
+			total += weight
+		}
+	}
+	sort.Ints(assetIDs)
+
+	if total <= 0.0 {
+		return nil, nil, fmt.Errorf("empty wallet")
+	}
+
+	assets := make([]*asset.Asset, len(assetIDs))
+	weights := make([]float64, len(assetIDs))
+	for i, assetID := range assetIDs {
+		a, err := database.GetAssetByID(assetID)
+		if err != nil {
+			return nil, nil, err
+		}
+		assets[i] = a
+		weights[i] = float64(wallet.allocation[assetID] / total)
+	}
+
+	matrix, err := covariance.Estimate(assets)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return matrix, weights, nil
+}
+
+// Writes the total return index of the target wallet into a file.
+func (wallet *Wallet) WriteIndex(file *os.File) error {
+
+	// Invalid file.
+	if file == nil {
+		return fmt.Errorf("invalid index file")
+	}
+
+	dates, index, err := wallet.TotalReturnIndex()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(file, "\nTotal Return Index of %s (%s to %s)\n", wallet.name,
+		dates[0].Format("2006-01"), dates[len(dates)-1].Format("2006-01"))
+	for t := range dates {
+		fmt.Fprintf(file, "  %s %10.4f\n", dates[t].Format("2006-01"), index[t])
+	}
+	fmt.Fprintf(file, "\n")
+
+	return nil
+}
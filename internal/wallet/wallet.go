@@ -28,17 +28,24 @@ import (
 	"bufio"
 	"fmt"
 	"os"
+	"portfolio/internal/asset"
 	"portfolio/internal/config"
 	"portfolio/internal/database"
 	"strings"
+	"time"
 )
 
 // Wallet
 type Wallet struct {
-	name        string          // Name
-	allocation  map[int]float32 // Allocation
-	performance float32         // Performance
-	price       float32         // Cost
+	name        string           // Name
+	allocation  map[int]float32  // Allocation
+	holdings    map[int]*Holding // Holdings (optional)
+	cash        float32          // Cash Balance
+	realized    float32          // Realized Gain
+	income      float32          // Dividend Income
+	date        time.Time        // Valuation Date (zero means latest prices)
+	performance float32          // Performance
+	price       float32          // Cost
 }
 
 // Creates an empty wallet.
@@ -47,6 +54,7 @@ func New(name string) *Wallet {
 
 	wallet.name = name
 	wallet.allocation = make(map[int]float32)
+	wallet.holdings = make(map[int]*Holding)
 
 	return wallet
 }
@@ -69,6 +77,16 @@ func (wallet *Wallet) SetAllocation(newAllocation map[int]float32) {
 	wallet.allocation = newAllocation
 }
 
+// Returns the name of the target wallet.
+func (wallet *Wallet) Name() string {
+	return wallet.name
+}
+
+// Returns the allocation of the target wallet.
+func (wallet *Wallet) Allocation() map[int]float32 {
+	return wallet.allocation
+}
+
 /*============================================================================*
  * Performance()                                                              *
  *============================================================================*/
@@ -109,32 +127,45 @@ func (wallet *Wallet) Cost() float32 {
  * Risk()                                                                     *
  *============================================================================*/
 
-// Computes the risk of the target wallet.
-func (wallet *Wallet) Risk() float32 {
+// Computes the risk of the target wallet, from its diversification across the
+// classes of the taxonomy and the safety of its assets, given the weights of
+// risk measures. Higher values mean safer wallets.
+func (wallet *Wallet) Risk(measures asset.RiskWeights) float32 {
 	var risk float32
-	classes := []float32{0, 0, 0, 0, 0}
 
-	// Compute asset allocation in each class.
 	for i := range wallet.allocation {
-
-		a, _ := database.GetAssetByID(i)
-		classes[a.Class()] += wallet.allocation[i]
+		if wallet.allocation[i] > 0.0 {
+			a, _ := database.GetAssetByID(i)
+			risk += a.Risk(measures) * wallet.allocation[i]
+		}
 	}
 
-	for i := range classes {
-		if classes[i] > risk {
-			risk = classes[i]
-		}
+	return 5 * (database.Diversification(wallet.classAllocation()) + 1.0/(1.0+risk))
+}
+
+// Returns the classes of the assets in the target wallet and the allocation
+// in these assets.
+func (wallet *Wallet) classAllocation() ([]int, []float32) {
+	classIDs := make([]int, 0, len(wallet.allocation))
+	allocation := make([]float32, 0, len(wallet.allocation))
+
+	for i, x := range wallet.allocation {
+		a, _ := database.GetAssetByID(i)
+		classIDs = append(classIDs, a.Class())
+		allocation = append(allocation, x)
 	}
 
-	return 10 * (1 - risk)
+	return classIDs, allocation
 }
 
 /*============================================================================*
  * Read()                                                                     *
  *============================================================================*/
 
-// Reads a wallet from a file.
+// Reads a wallet from a file. Each line after the name of the wallet is
+// either "<ticker> <percent>", "<ticker> <percent> <quantity> <average price>"
+// or "cash <amount>". When quantities are given, the allocation is computed
+// from the market value of the holdings.
 func Read(filename string) (*Wallet, error) {
 
 	file, err := os.Open(config.WalletsPath + filename)
@@ -144,7 +175,6 @@ func Read(filename string) (*Wallet, error) {
 	defer file.Close()
 
 	reader := bufio.NewReader(file)
-	allocation := make(map[int]float32)
 
 	// Read wallet name.
 	line, err := reader.ReadString('\n')
@@ -153,30 +183,43 @@ func Read(filename string) (*Wallet, error) {
 	}
 	name := strings.TrimSuffix(line, "\n")
 
+	// Instantiate wallet.
+	wallet := New(name)
+
 	// Read allocations
 	for {
 		var a float32
 		var assetID int
 		var assetTicker string
+		var quantity int
+		var averagePrice float32
 
 		line, err = reader.ReadString('\n')
 		if err != nil {
 			break
 		}
 
-		fmt.Sscanf(line, "%s %f", &assetTicker, &a)
+		n, _ := fmt.Sscanf(line, "%s %f %d %f", &assetTicker, &a, &quantity, &averagePrice)
+
+		// Cash balance.
+		if assetTicker == "cash" {
+			wallet.cash = a
+			continue
+		}
 
 		assetID, err = database.GetAssetID(assetTicker)
 		if err != nil {
 			break
 		}
-		allocation[assetID] = a / 100.0
+		wallet.allocation[assetID] = a / 100.0
+
+		// Holding.
+		if n == 4 && quantity > 0 {
+			wallet.holdings[assetID] = &Holding{quantity, averagePrice}
+		}
 	}
 
-	// Instantiate wallet.
-	wallet := &Wallet{}
-	wallet.name = name
-	wallet.allocation = allocation
+	wallet.updateAllocation()
 
 	return wallet, nil
 }
@@ -197,13 +240,21 @@ func (wallet *Wallet) Persist(filename string) error {
 	fmt.Fprintf(file, "%s\n", wallet.name)
 	for assetID, assetAllocation := range wallet.allocation {
 		if assetAllocation > 0.0 {
+			ticker, _ := database.AssetTicker(assetID)
 			fmt.Fprintf(file,
-				"%d %5.2f\n",
-				assetID,
+				"%s %5.2f",
+				ticker,
 				assetAllocation*100.0,
 			)
+			if h, ok := wallet.holdings[assetID]; ok {
+				fmt.Fprintf(file, " %d %.2f", h.Quantity, h.AveragePrice)
+			}
+			fmt.Fprintf(file, "\n")
 		}
 	}
+	if wallet.cash != 0.0 {
+		fmt.Fprintf(file, "cash %.2f\n", wallet.cash)
+	}
 
 	return nil
 }
@@ -225,12 +276,27 @@ func (wallet *Wallet) Write(file *os.File) error {
 		if assetAllocation > 0.0 {
 			ticker, _ := database.AssetTicker(assetID)
 			fmt.Fprintf(file,
-				"  %s %5.2f\n",
+				"  %s %5.2f",
 				ticker,
 				assetAllocation*100.0,
 			)
+			if h, ok := wallet.holdings[assetID]; ok {
+				price := wallet.sharePrice(assetID, h)
+				fmt.Fprintf(file,
+					" %6d x %8.2f (avg. %8.2f) = %10.2f (%+.2f)",
+					h.Quantity,
+					price,
+					h.AveragePrice,
+					h.Value(price),
+					h.Value(price)-h.Cost(),
+				)
+			}
+			fmt.Fprintf(file, "\n")
 		}
 	}
+	if wallet.HasHoldings() || wallet.cash != 0.0 {
+		fmt.Fprintf(file, "  %-6s %10.2f\n", "cash", wallet.cash)
+	}
 
 	return nil
 }
@@ -239,34 +305,76 @@ func (wallet *Wallet) Write(file *os.File) error {
  * PrintStats()                                                               *
  *============================================================================*/
 
-func (wallet *Wallet) PrintStats(file *os.File) {
-	classes := []float32{0, 0, 0, 0, 0}
-
+// Prints statistics of the target wallet, with its risk computed from the
+// given weights of risk measures.
+func (wallet *Wallet) PrintStats(file *os.File, measures asset.RiskWeights) {
 	fmt.Fprintf(file, "\nStatistics for %s\n", wallet.name)
 
-	// Compute asset allocation in each class.
-	for i := range wallet.allocation {
-		a, _ := database.GetAssetByID(i)
-		classes[a.Class()] += wallet.allocation[i]
-	}
-
+	// Compute asset allocation in each class, skipping empty sub-classes.
+	classes := database.ClassAllocation(wallet.classAllocation())
 	for i := range classes {
+		depth := database.ClassDepth(i)
+		if depth > 0 && classes[i] == 0.0 {
+			continue
+		}
+
 		className, _ := database.GetClassName(i)
 
 		fmt.Fprintf(file,
-			"  %-15s %5.2f %%\n",
-			className,
+			"  %-20s %5.2f %%\n",
+			strings.Repeat("  ", depth)+className,
 			100*classes[i],
 		)
 	}
 
+	// Compute asset allocation in each tag.
+	if tags := database.Tags(); len(tags) > 0 {
+		fmt.Fprintf(file, "\n")
+		for _, tag := range tags {
+			var allocation float32
+			for i := range wallet.allocation {
+				if database.HasTag(i, tag) {
+					allocation += wallet.allocation[i]
+				}
+			}
+
+			fmt.Fprintf(file,
+				"  %-20s %5.2f %%\n",
+				"#"+tag,
+				100*allocation,
+			)
+		}
+	}
+
 	performance := wallet.Performance()
 	cost := wallet.Cost()
-	risk := wallet.Risk()
+	risk := wallet.Risk(measures)
 	score := (performance + cost + risk) / 3.0
 
 	fmt.Fprintf(file, "\n  %-15s %5.2f %%\n", "Performance", performance)
 	fmt.Fprintf(file, "  %-15s %5.2f %%\n", "Cost", cost)
 	fmt.Fprintf(file, "  %-15s %5.2f %%\n", "Risk", risk)
-	fmt.Fprintf(file, "  %-15s %5.2f %%\n\n", "Score", score)
+	fmt.Fprintf(file, "  %-15s %5.2f %%\n", "Score", score)
+	if dates, index, err := wallet.TotalReturnIndex(); err == nil {
+		fmt.Fprintf(file, "  %-15s %5.2f %% (%s to %s)\n", "Total Return", 100*annualize(dates, index),
+			dates[0].Format("2006-01"), dates[len(dates)-1].Format("2006-01"))
+	}
+	if matrix, weights, err := wallet.Covariance(); err == nil {
+		fmt.Fprintf(file, "  %-15s %5.2f %%\n", "Volatility", 100*matrix.PortfolioVolatility(weights))
+		fmt.Fprintf(file, "  %-15s %5.2f\n", "Div. Ratio", matrix.DiversificationRatio(weights))
+		fmt.Fprintf(file, "  %-15s %5.2f\n", "Eff. Bets", matrix.EffectiveBets(weights))
+	}
+	fmt.Fprintf(file, "\n")
+
+	if wallet.HasHoldings() || wallet.cash != 0.0 {
+		fmt.Fprintf(file, "  %-15s %10.2f\n", "Market Value", wallet.MarketValue())
+		fmt.Fprintf(file, "  %-15s %10.2f\n", "Cash", wallet.cash)
+		fmt.Fprintf(file, "  %-15s %10.2f\n", "Cost Basis", wallet.CostBasis())
+		fmt.Fprintf(file, "  %-15s %10.2f\n", "Unrealized Gain", wallet.UnrealizedGain())
+		if wallet.realized != 0.0 || wallet.income != 0.0 {
+			fmt.Fprintf(file, "  %-15s %10.2f\n", "Realized Gain", wallet.realized)
+			fmt.Fprintf(file, "  %-15s %10.2f\n", "Dividend Income", wallet.income)
+		}
+		fmt.Fprintf(file, "\n")
+	}
 }
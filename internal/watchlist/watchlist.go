@@ -32,6 +32,7 @@ import (
 	"portfolio/internal/config"
 	"portfolio/internal/database"
 	"strings"
+	"time"
 )
 
 // Watchlist
@@ -65,11 +66,16 @@ func (w *Watchlist) Load(filename string) error {
 
 		// Done.
 		line, err := reader.ReadString('\n')
-		if err != nil {
+		if err != nil && line == "" {
 			break
 		}
 
-		ticker := strings.TrimSuffix(line, "\n")
+		// Skip blank lines.
+		ticker := strings.TrimSpace(line)
+		if ticker == "" {
+			continue
+		}
+
 		a, err := database.GetAssetByTicker(ticker)
 		if err != nil {
 			fmt.Println(err.Error())
@@ -86,3 +92,18 @@ func (w *Watchlist) Load(filename string) error {
 func (w *Watchlist) Assets() []*asset.Asset {
 	return w.assets
 }
+
+// Returns a watchlist with views of the assets of the target watchlist as of a
+// given date, with statistics computed over the last lookback months (zero
+// means the whole history). Assets without enough history are left out.
+func (w *Watchlist) AsOf(date time.Time, lookback int) *Watchlist {
+	past := New()
+
+	for _, a := range w.assets {
+		if view := a.AsOfWindow(date, lookback); view != nil {
+			past.assets = append(past.assets, view)
+		}
+	}
+
+	return past
+}